@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"hippo/internal/ndcdir"
+	"hippo/internal/service"
+)
+
+const usage = `usage: app [command]
+
+Without a command the HTTP server is started.
+
+commands:
+  backfill-forms [-dry-run]   rewrite medicine forms to canonical dosage form codes
+  import-ndc [-dry-run] DIR   upsert medicines from the FDA NDC directory export in DIR
+                              (product.txt and package.txt)
+`
+
+// runCommand runs a one-off maintenance command and prints its report as
+// JSON to stdout.
+func runCommand(ctx context.Context, args []string, medicines *service.Medicines) error {
+	// audit events are sent in the background, the process must not exit first
+	defer medicines.WaitAudits()
+
+	switch args[0] {
+	case "backfill-forms":
+		fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+		dryRun := fs.Bool("dry-run", false, "report the changes without writing them")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		report, err := medicines.BackfillForms(ctx, *dryRun)
+		if err != nil {
+			return err
+		}
+		return printReport(report)
+
+	case "import-ndc":
+		fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+		dryRun := fs.Bool("dry-run", false, "report the changes without writing them")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			fmt.Fprint(os.Stderr, usage)
+			return fmt.Errorf("%s: expected the directory of the export", args[0])
+		}
+
+		rows, err := ndcdir.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		report, err := medicines.Import(ctx, rows, *dryRun)
+		if err != nil {
+			return err
+		}
+		return printReport(report)
+
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return nil
+	}
+
+	fmt.Fprint(os.Stderr, usage)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func printReport(report any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
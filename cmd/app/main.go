@@ -20,6 +20,7 @@ import (
 	"hippo/internal/repository/psql"
 	"hippo/internal/service"
 	"hippo/internal/transport/rest"
+	"hippo/internal/worker"
 	"hippo/pkg/hash"
 )
 
@@ -76,8 +77,56 @@ func main() {
 		logger.Int("port", cfg.GrpcAudit.Port),
 	)
 
+	medicinesRepo := psql.NewMedicines(db)
+	dosageFormsRepo := psql.NewDosageForms(db)
+	manufacturersRepo := psql.NewManufacturers(db)
+	categoriesRepo := psql.NewCategories(db)
+
 	medicineService := service.NewMedicines(
-		psql.NewMedicines(db),
+		medicinesRepo,
+		psql.NewMedicineRevisions(db),
+		dosageFormsRepo,
+		manufacturersRepo,
+		categoriesRepo,
+		auditService,
+		log,
+		cfg.App.DeletedRetention,
+		cfg.App.StrictDosageForms,
+	)
+
+	if len(os.Args) > 1 {
+		if err = runCommand(context.Background(), os.Args[1:], medicineService); err != nil {
+			log.Error("command failed", logger.String("command", os.Args[1]), logger.Err(err))
+			// runCommand has already waited for its audit events
+			os.Exit(1)
+		}
+		return
+	}
+
+	dosageFormsService := service.NewDosageForms(dosageFormsRepo, log)
+	manufacturersService := service.NewManufacturers(manufacturersRepo, medicinesRepo, log)
+	categoriesService := service.NewCategories(categoriesRepo, medicinesRepo, log)
+
+	lotsRepo := psql.NewLots(db)
+
+	stockService := service.NewStock(
+		psql.NewStock(db),
+		medicinesRepo,
+		lotsRepo,
+		auditService,
+		log,
+	)
+
+	lotsService := service.NewLots(
+		lotsRepo,
+		medicinesRepo,
+		auditService,
+		log,
+	)
+
+	interactionsService := service.NewInteractions(
+		psql.NewInteractions(db),
+		medicinesRepo,
 		auditService,
 		log,
 	)
@@ -93,7 +142,23 @@ func main() {
 		log,
 	)
 
-	handler := rest.NewHandler(medicineService, usersService, log, cfg.App.HandlerTimeout)
+	if err = usersService.EnsureAdmins(context.Background(), cfg.App.AdminUserIDs); err != nil {
+		log.Fatal("failed to grant configured admin roles", logger.Err(err))
+	}
+
+	handler := rest.NewHandler(
+		medicineService,
+		stockService,
+		lotsService,
+		interactionsService,
+		dosageFormsService,
+		manufacturersService,
+		categoriesService,
+		usersService,
+		log,
+		cfg.App.HandlerTimeout,
+		cfg.App.BulkTimeout,
+	)
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.HttpServer.Port),
 		Handler:      handler.InitRouter(),
@@ -102,6 +167,23 @@ func main() {
 		IdleTimeout:  cfg.HttpServer.Idle,
 	}
 
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+
+	if cfg.Expiry.Enabled {
+		expiry := worker.NewExpiry(lotsService, cfg.Expiry.Interval, cfg.Expiry.WarnWithin, log)
+		go func() {
+			defer close(workersDone)
+			expiry.Run(workerCtx)
+		}()
+
+		log.Info("Expiry worker started",
+			logger.String("interval", cfg.Expiry.Interval.String()),
+		)
+	} else {
+		close(workersDone)
+	}
+
 	shutdownChan := make(chan os.Signal, 1)
 	signal.Notify(shutdownChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -126,4 +208,10 @@ func main() {
 	}
 
 	log.Info("HTTP server stopped")
+
+	stopWorkers()
+	<-workersDone
+	lotsService.WaitAudits()
+
+	log.Info("background workers stopped")
 }
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Category is a node of the therapeutic classification tree, such as an ATC
+// group. Root categories have no parent.
+type Category struct {
+	ID        int64     `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	ParentID  *int64    `json:"parent_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpdateCategory moves a category to the root when ParentID points at 0.
+type UpdateCategory struct {
+	Code     *string `json:"code"`
+	Name     *string `json:"name"`
+	ParentID *int64  `json:"parent_id"`
+}
+
+// NormalizeCategoryCode uppercases a code and drops surrounding whitespace,
+// so "a10ba02" and "A10BA02" name the same category.
+func NormalizeCategoryCode(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// CategoriesLabel renders category codes in a stable order for revision diffs.
+func CategoriesLabel(codes []string) string {
+	sorted := append([]string(nil), codes...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
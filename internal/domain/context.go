@@ -0,0 +1,18 @@
+package domain
+
+import "context"
+
+type ctxKey int
+
+const ctxUserIDKey ctxKey = iota
+
+// WithUserID stores the id of the authenticated user acting on a request.
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, ctxUserIDKey, userID)
+}
+
+// UserIDFromContext returns the acting user id, if the request is authenticated.
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(ctxUserIDKey).(int64)
+	return id, ok
+}
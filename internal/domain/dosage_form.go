@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// DosageForm is an entry of the managed form vocabulary. Medicines store the
+// Code; Synonyms are the other spellings that normalize to it.
+type DosageForm struct {
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Synonyms  []string  `json:"synonyms"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UpdateDosageForm struct {
+	Name     *string   `json:"name"`
+	Synonyms *[]string `json:"synonyms"`
+}
+
+// NormalizeFormTerm is the lookup key for codes and synonyms: lowercase,
+// single-spaced and without a trailing period, so "Tab." matches "tab".
+func NormalizeFormTerm(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	return strings.TrimSuffix(s, ".")
+}
+
+// FormChange is one medicine whose form was rewritten to a canonical code.
+type FormChange struct {
+	MedicineID int64  `json:"medicine_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+type FormBackfillReport struct {
+	DryRun  bool           `json:"dry_run"`
+	Scanned int            `json:"scanned"`
+	Changed int            `json:"changed"`
+	Failed  int            `json:"failed"`
+	Unknown map[string]int `json:"unknown"`
+	Changes []FormChange   `json:"changes"`
+}
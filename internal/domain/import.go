@@ -0,0 +1,58 @@
+package domain
+
+type ImportStatus string
+
+const (
+	ImportCreated   ImportStatus = "created"
+	ImportUpdated   ImportStatus = "updated"
+	ImportUnchanged ImportStatus = "unchanged"
+	ImportRejected  ImportStatus = "rejected"
+	ImportDuplicate ImportStatus = "duplicate"
+)
+
+// ImportRow is one decoded record of an import file. Err is set when the
+// record itself could not be decoded.
+type ImportRow struct {
+	Line     int
+	Medicine Medicine
+	Err      error
+}
+
+// ImportRowResult is the outcome of one row. Changes lists the fields an
+// update rewrote.
+type ImportRowResult struct {
+	Line    int           `json:"line"`
+	NDC     string        `json:"ndc,omitempty"`
+	ID      int64         `json:"id,omitempty"`
+	Status  ImportStatus  `json:"status"`
+	Reason  string        `json:"reason,omitempty"`
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun     bool              `json:"dry_run"`
+	Total      int               `json:"total"`
+	Created    int               `json:"created"`
+	Updated    int               `json:"updated"`
+	Unchanged  int               `json:"unchanged"`
+	Rejected   int               `json:"rejected"`
+	Duplicates int               `json:"duplicates"`
+	Rows       []ImportRowResult `json:"rows"`
+}
+
+func (r *ImportReport) Add(res ImportRowResult) {
+	r.Total++
+	switch res.Status {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportUnchanged:
+		r.Unchanged++
+	case ImportRejected:
+		r.Rejected++
+	case ImportDuplicate:
+		r.Duplicates++
+	}
+	r.Rows = append(r.Rows, res)
+}
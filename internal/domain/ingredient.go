@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"strings"
+
+	"hippo/pkg/strength"
+)
+
+// Ingredient is an entry of the active ingredient catalog.
+type Ingredient struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// MedicineIngredient is one active ingredient of a medicine together with
+// its strength in that product. Strength is nil when the label does not
+// state it per ingredient.
+type MedicineIngredient struct {
+	IngredientID int64              `json:"ingredient_id,omitempty"`
+	Name         string             `json:"name"`
+	Strength     *strength.Strength `json:"strength,omitempty"`
+}
+
+// SplitIngredients breaks a combination label such as
+// "amoxicillin/clavulanate" or "ibuprofen + caffeine" into its ingredient
+// names, single-spaced and in label order.
+func SplitIngredients(s string) []string {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '/' || r == '+' })
+
+	names := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if name := strings.Join(strings.Fields(p), " "); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IngredientsLabel renders ingredients in label order, with their strengths
+// where known, for example "amoxicillin 875 mg / clavulanate 125 mg".
+func IngredientsLabel(ingredients []MedicineIngredient) string {
+	parts := make([]string, len(ingredients))
+	for i, in := range ingredients {
+		parts[i] = in.Name
+		if in.Strength != nil {
+			parts[i] += " " + in.Strength.String()
+		}
+	}
+	return strings.Join(parts, " / ")
+}
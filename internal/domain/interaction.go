@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type InteractionSeverity string
+
+const (
+	SeverityMinor           InteractionSeverity = "minor"
+	SeverityModerate        InteractionSeverity = "moderate"
+	SeverityMajor           InteractionSeverity = "major"
+	SeverityContraindicated InteractionSeverity = "contraindicated"
+)
+
+func (s InteractionSeverity) Valid() bool {
+	switch s {
+	case SeverityMinor, SeverityModerate, SeverityMajor, SeverityContraindicated:
+		return true
+	}
+	return false
+}
+
+// Interaction is a known interaction between two active ingredients. The
+// pair is stored normalized and ordered so that IngredientA < IngredientB.
+type Interaction struct {
+	ID          int64               `json:"id"`
+	IngredientA string              `json:"ingredient_a"`
+	IngredientB string              `json:"ingredient_b"`
+	Severity    InteractionSeverity `json:"severity"`
+	Description string              `json:"description"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+type UpdateInteraction struct {
+	Severity    *InteractionSeverity `json:"severity"`
+	Description *string              `json:"description"`
+}
+
+// NormalizeIngredient lowercases an ingredient name and collapses its
+// whitespace so that registry lookups do not depend on label formatting.
+func NormalizeIngredient(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// IngredientPair returns both ingredients normalized and ordered.
+func IngredientPair(a, b string) (string, string) {
+	a, b = NormalizeIngredient(a), NormalizeIngredient(b)
+	if b < a {
+		a, b = b, a
+	}
+	return a, b
+}
+
+type InteractionCheckRequest struct {
+	MedicineIDs []int64 `json:"medicine_ids"`
+}
+
+// InteractionFinding is one registry entry that applies to a pair of the
+// checked medicines.
+type InteractionFinding struct {
+	MedicineA   int64       `json:"medicine_a"`
+	MedicineB   int64       `json:"medicine_b"`
+	Interaction Interaction `json:"interaction"`
+}
+
+type InteractionCheckResult struct {
+	MedicineIDs  []int64              `json:"medicine_ids"`
+	Interactions []InteractionFinding `json:"interactions"`
+}
+
+// InteractionImportRow is one decoded record of an interaction import file.
+type InteractionImportRow struct {
+	Line        int
+	Interaction Interaction
+	Err         error
+}
+
+type InteractionImportRowResult struct {
+	Line        int          `json:"line"`
+	IngredientA string       `json:"ingredient_a,omitempty"`
+	IngredientB string       `json:"ingredient_b,omitempty"`
+	ID          int64        `json:"id,omitempty"`
+	Status      ImportStatus `json:"status"`
+	Reason      string       `json:"reason,omitempty"`
+}
+
+type InteractionImportReport struct {
+	DryRun     bool                         `json:"dry_run"`
+	Total      int                          `json:"total"`
+	Created    int                          `json:"created"`
+	Updated    int                          `json:"updated"`
+	Unchanged  int                          `json:"unchanged"`
+	Rejected   int                          `json:"rejected"`
+	Duplicates int                          `json:"duplicates"`
+	Rows       []InteractionImportRowResult `json:"rows"`
+}
+
+func (r *InteractionImportReport) Add(res InteractionImportRowResult) {
+	r.Total++
+	switch res.Status {
+	case ImportCreated:
+		r.Created++
+	case ImportUpdated:
+		r.Updated++
+	case ImportUnchanged:
+		r.Unchanged++
+	case ImportRejected:
+		r.Rejected++
+	case ImportDuplicate:
+		r.Duplicates++
+	}
+	r.Rows = append(r.Rows, res)
+}
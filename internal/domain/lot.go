@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+type LotStatus string
+
+const (
+	LotActive      LotStatus = "active"
+	LotQuarantined LotStatus = "quarantined"
+)
+
+// Lot is one received batch of a medicine. OnHand is summed from the stock
+// ledger entries that reference the lot.
+type Lot struct {
+	ID         int64     `json:"id"`
+	MedicineID int64     `json:"medicine_id"`
+	LotNumber  string    `json:"lot_number"`
+	ExpiresAt  Date      `json:"expires_at"`
+	Status     LotStatus `json:"status"`
+	OnHand     int       `json:"on_hand"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type UpdateLot struct {
+	LotNumber *string `json:"lot_number"`
+	ExpiresAt *Date   `json:"expires_at"`
+}
+
+// Expired reports whether the lot can no longer be dispensed on day now.
+func (l Lot) Expired(now time.Time) bool {
+	return !NewDate(now).Before(l.ExpiresAt.Time)
+}
+
+// LotBalance is the on-hand quantity of one lot at one location.
+type LotBalance struct {
+	LotID     int64 `json:"lot_id"`
+	ExpiresAt Date  `json:"expires_at"`
+	OnHand    int   `json:"on_hand"`
+}
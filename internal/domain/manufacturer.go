@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Manufacturer is a pharma company. Medicines reference it by ID and keep
+// its name in PharmaCompany for clients that only read the string.
+type Manufacturer struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type UpdateManufacturer struct {
+	Name *string `json:"name"`
+}
+
+// NormalizeManufacturerName is the key manufacturers are deduplicated on:
+// lowercase and single-spaced.
+func NormalizeManufacturerName(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
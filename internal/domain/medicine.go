@@ -1,20 +1,133 @@
 package domain
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"hippo/pkg/strength"
+)
+
+// Medicine is a catalog entry. Dosage holds the label strength as text and
+// Strength is its parsed form; it is nil for text that does not parse, such
+// as "1%" or "875 mg/125 mg".
+// Ingredients lists the active ingredients of the product and
+// ActiveIngredient is their names joined as printed on combination labels.
+// Categories holds the codes of the categories the medicine is filed under.
 type Medicine struct {
-	ID               int    `json:"id"`
-	NDC              string `json:"ndc"`
-	Name             string `json:"name"`
-	Dosage           string `json:"dosage"`
-	Form             string `json:"form"`
-	ActiveIngredient string `json:"active_ingredient"`
-	PharmaCompany    string `json:"pharma_company"`
+	ID               int                  `json:"id"`
+	NDC              string               `json:"ndc"`
+	Name             string               `json:"name"`
+	Dosage           string               `json:"dosage"`
+	Strength         *strength.Strength   `json:"strength,omitempty"`
+	Form             string               `json:"form"`
+	ActiveIngredient string               `json:"active_ingredient"`
+	Ingredients      []MedicineIngredient `json:"ingredients"`
+	PharmaCompany    string               `json:"pharma_company"`
+	ManufacturerID   *int64               `json:"manufacturer_id,omitempty"`
+	Categories       []string             `json:"categories"`
+	DeletedAt        *time.Time           `json:"deleted_at,omitempty"`
+	Version          int                  `json:"version"`
 }
 
+// UpdateMedicine changes the strength through either Dosage or Strength, the
+// manufacturer through either PharmaCompany or ManufacturerID and the
+// ingredients through either ActiveIngredient or Ingredients.
 type UpdateMedicine struct {
-	NDC              *string `json:"ndc"`
-	Name             *string `json:"name"`
-	Dosage           *string `json:"dosage"`
-	Form             *string `json:"form"`
-	ActiveIngredient *string `json:"active_ingredient"`
-	PharmaCompany    *string `json:"pharma_company"`
+	NDC              *string               `json:"ndc"`
+	Name             *string               `json:"name"`
+	Dosage           *string               `json:"dosage"`
+	Strength         *strength.Strength    `json:"strength"`
+	Form             *string               `json:"form"`
+	ActiveIngredient *string               `json:"active_ingredient"`
+	Ingredients      *[]MedicineIngredient `json:"ingredients"`
+	PharmaCompany    *string               `json:"pharma_company"`
+	ManufacturerID   *int64                `json:"manufacturer_id"`
+	Categories       *[]string             `json:"categories"`
+}
+
+// ParseStrength returns the structured form of a dosage, or nil if the text
+// is empty or not a recognizable strength.
+func ParseStrength(dosage string) *strength.Strength {
+	st, err := strength.Parse(dosage)
+	if err != nil {
+		return nil
+	}
+	return &st
+}
+
+type MedicineSortField string
+
+const (
+	MedicineSortByID   MedicineSortField = "id"
+	MedicineSortByName MedicineSortField = "name"
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// MedicineFilter narrows a medicine listing. Empty fields are ignored.
+type MedicineFilter struct {
+	NDC              string
+	Form             string
+	PharmaCompany    string
+	ActiveIngredient string
+	// Ingredient matches medicines containing the ingredient, alone or in
+	// a combination
+	Ingredient     string
+	ManufacturerID int64
+	// CategoryID matches medicines filed under the category, or anywhere
+	// in its subtree when CategoryRecursive is set
+	CategoryID        int64
+	CategoryRecursive bool
+}
+
+// MedicineCursor is the keyset position of the last row of a page.
+type MedicineCursor struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func (c MedicineCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func DecodeMedicineCursor(s string) (MedicineCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return MedicineCursor{}, errors.New("malformed cursor")
+	}
+
+	var c MedicineCursor
+	if err = json.Unmarshal(b, &c); err != nil || c.ID <= 0 {
+		return MedicineCursor{}, errors.New("malformed cursor")
+	}
+
+	return c, nil
+}
+
+type MedicineListOptions struct {
+	Filter         MedicineFilter
+	SortBy         MedicineSortField
+	Order          SortOrder
+	Limit          int
+	After          *MedicineCursor
+	IncludeDeleted bool
+}
+
+type MedicinePage struct {
+	Items      []Medicine `json:"items"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+	Total      int64      `json:"total"`
+}
+
+type MedicineSearchResult struct {
+	Medicine
+	Score float64 `json:"score"`
 }
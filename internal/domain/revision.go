@@ -0,0 +1,125 @@
+package domain
+
+import "time"
+
+type RevisionAction string
+
+const (
+	RevisionCreate  RevisionAction = "create"
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
+	RevisionRevert  RevisionAction = "revert"
+)
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// MedicineRevision is one recorded change of a medicine. Snapshot holds the
+// medicine as it was right after the change (right before it, for deletes).
+type MedicineRevision struct {
+	ID         int64          `json:"-"`
+	MedicineID int64          `json:"medicine_id"`
+	Revision   int            `json:"revision"`
+	Action     RevisionAction `json:"action"`
+	Changes    []FieldChange  `json:"changes"`
+	Snapshot   Medicine       `json:"snapshot"`
+	UserID     int64          `json:"user_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// Apply returns a copy of m with every non-nil field of upd set.
+func (m Medicine) Apply(upd UpdateMedicine) Medicine {
+	if upd.NDC != nil {
+		m.NDC = *upd.NDC
+	}
+	if upd.Name != nil {
+		m.Name = *upd.Name
+	}
+	if upd.Dosage != nil {
+		m.Dosage = *upd.Dosage
+		m.Strength = ParseStrength(m.Dosage)
+	}
+	if upd.Form != nil {
+		m.Form = *upd.Form
+	}
+	if upd.ActiveIngredient != nil {
+		m.ActiveIngredient = *upd.ActiveIngredient
+	}
+	if upd.Ingredients != nil {
+		m.Ingredients = append([]MedicineIngredient(nil), (*upd.Ingredients)...)
+	}
+	if upd.PharmaCompany != nil {
+		m.PharmaCompany = *upd.PharmaCompany
+	}
+	if upd.Categories != nil {
+		m.Categories = append([]string(nil), (*upd.Categories)...)
+	}
+	if upd.ManufacturerID != nil {
+		m.ManufacturerID = nil
+		if *upd.ManufacturerID > 0 {
+			id := *upd.ManufacturerID
+			m.ManufacturerID = &id
+		}
+	}
+	return m
+}
+
+// Diff lists the catalog fields that differ between m and other, using the
+// JSON field names.
+func (m Medicine) Diff(other Medicine) []FieldChange {
+	pairs := []struct {
+		field    string
+		old, new string
+	}{
+		{"ndc", m.NDC, other.NDC},
+		{"name", m.Name, other.Name},
+		{"dosage", m.Dosage, other.Dosage},
+		{"form", m.Form, other.Form},
+		{"active_ingredient", m.ActiveIngredient, other.ActiveIngredient},
+		{"ingredients", IngredientsLabel(m.Ingredients), IngredientsLabel(other.Ingredients)},
+		{"pharma_company", m.PharmaCompany, other.PharmaCompany},
+		{"categories", CategoriesLabel(m.Categories), CategoriesLabel(other.Categories)},
+	}
+
+	changes := make([]FieldChange, 0)
+	for _, p := range pairs {
+		if p.old != p.new {
+			changes = append(changes, FieldChange{Field: p.field, Old: p.old, New: p.new})
+		}
+	}
+	return changes
+}
+
+// UpdateTo builds the update that turns m into target.
+func (m Medicine) UpdateTo(target Medicine) UpdateMedicine {
+	var upd UpdateMedicine
+	for _, c := range m.Diff(target) {
+		v := c.New
+		switch c.Field {
+		case "ndc":
+			upd.NDC = &v
+		case "name":
+			upd.Name = &v
+		case "dosage":
+			upd.Dosage = &v
+		case "form":
+			upd.Form = &v
+		case "active_ingredient":
+			upd.ActiveIngredient = &v
+		case "ingredients":
+			// an empty list is derived again from active_ingredient
+			ingredients := append([]MedicineIngredient{}, target.Ingredients...)
+			upd.Ingredients = &ingredients
+		case "pharma_company":
+			upd.PharmaCompany = &v
+		case "categories":
+			categories := append([]string{}, target.Categories...)
+			upd.Categories = &categories
+		}
+	}
+	return upd
+}
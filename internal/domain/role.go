@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Role is a named set of permissions granted to a user.
+type Role string
+
+const (
+	RoleViewer     Role = "viewer"
+	RolePharmacist Role = "pharmacist"
+	RoleAdmin      Role = "admin"
+
+	// DefaultRole is granted to every user on sign-up.
+	DefaultRole = RoleViewer
+)
+
+// Permission guards a group of endpoints.
+type Permission string
+
+const (
+	PermCatalogRead  Permission = "catalog:read"
+	PermCatalogWrite Permission = "catalog:write"
+	PermStockWrite   Permission = "stock:write"
+	PermAdmin        Permission = "admin"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer:     {PermCatalogRead},
+	RolePharmacist: {PermCatalogRead, PermCatalogWrite, PermStockWrite},
+	RoleAdmin:      {PermCatalogRead, PermCatalogWrite, PermStockWrite, PermAdmin},
+}
+
+// ParseRole accepts the names of the known roles.
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := rolePermissions[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", s)
+	}
+	return role, nil
+}
+
+// Principal is the authenticated caller of a request as described by its
+// access token.
+type Principal struct {
+	UserID int64
+	Roles  []Role
+}
+
+// Can reports whether any role of the principal grants perm.
+func (p Principal) Can(perm Permission) bool {
+	for _, role := range p.Roles {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+const DefaultStockLocation = "main"
+
+type StockMovementType string
+
+const (
+	StockReceive  StockMovementType = "receive"
+	StockDispense StockMovementType = "dispense"
+	StockAdjust   StockMovementType = "adjust"
+	StockTransfer StockMovementType = "transfer"
+)
+
+// StockMovement is one append-only ledger entry. Quantity is signed: stock
+// coming in is positive, stock going out is negative. A transfer is stored
+// as two entries sharing a Reference.
+type StockMovement struct {
+	ID         int64             `json:"id"`
+	MedicineID int64             `json:"medicine_id"`
+	Location   string            `json:"location"`
+	LotID      *int64            `json:"lot_id,omitempty"`
+	Type       StockMovementType `json:"type"`
+	Quantity   int               `json:"quantity"`
+	Reason     string            `json:"reason,omitempty"`
+	Reference  string            `json:"reference,omitempty"`
+	UserID     int64             `json:"user_id,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// StockItem is the on-hand quantity of a medicine at one location, as
+// summed from the ledger.
+type StockItem struct {
+	MedicineID int64  `json:"medicine_id"`
+	Location   string `json:"location"`
+	OnHand     int    `json:"on_hand"`
+}
+
+type StockLevel struct {
+	MedicineID int64       `json:"medicine_id"`
+	OnHand     int         `json:"on_hand"`
+	Locations  []StockItem `json:"locations"`
+}
+
+// StockRequest is the body of receive, dispense, adjust and transfer calls.
+// Quantity is positive except for adjust, where its sign is the direction.
+// Without LotID a dispense is allocated over lots first-expired-first-out.
+type StockRequest struct {
+	Quantity   int    `json:"quantity"`
+	LotID      *int64 `json:"lot_id"`
+	Location   string `json:"location"`
+	ToLocation string `json:"to_location"`
+	Reason     string `json:"reason"`
+	Reference  string `json:"reference"`
+}
@@ -2,9 +2,23 @@ package domain
 
 import "time"
 
+// RefreshSession is a signed-in device. Its refresh token is replaced on
+// every refresh, while the ID and CreatedAt stay the same. Only the SHA-256
+// hash of the token is kept.
 type RefreshSession struct {
-	ID        int64
-	UserID    int64
-	Token     string
-	ExpiresAt time.Time
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"-"`
+	TokenHash  string    `json:"-"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// SessionClient describes the device a session is opened or refreshed from.
+type SessionClient struct {
+	UserAgent string
+	IP        string
 }
@@ -18,6 +18,7 @@ type User struct {
 	Email     string    `json:"email"`
 	Password  string    `json:"password"`
 	CreatedAt time.Time `json:"registered_at"`
+	Roles     []Role    `json:"roles"`
 }
 
 type SignUpInfo struct {
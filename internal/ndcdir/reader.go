@@ -0,0 +1,260 @@
+// Package ndcdir reads the FDA NDC directory export: the tab-delimited
+// product.txt and package.txt files published at
+// https://www.fda.gov/drugs/drug-approvals-and-databases/national-drug-code-directory.
+//
+// Every package becomes one medicine keyed by its package NDC and carries
+// the fields of the product it belongs to.
+package ndcdir
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"hippo/internal/domain"
+	"hippo/pkg/strength"
+)
+
+const (
+	ProductFile = "product.txt"
+	PackageFile = "package.txt"
+
+	maxLineBytes = 1 << 20
+)
+
+var (
+	productColumns = []string{
+		"PRODUCTID", "PROPRIETARYNAME", "PROPRIETARYNAMESUFFIX", "NONPROPRIETARYNAME",
+		"DOSAGEFORMNAME", "LABELERNAME", "SUBSTANCENAME", "ACTIVE_NUMERATOR_STRENGTH", "ACTIVE_INGRED_UNIT",
+	}
+	packageColumns = []string{"PRODUCTID", "NDCPACKAGECODE"}
+)
+
+// Reader yields one import row per line of package.txt. It implements
+// service.MedicineRowReader.
+type Reader struct {
+	products map[string]domain.Medicine
+	packages *table
+	file     *os.File
+}
+
+// Open loads the products of the directory export in dir and opens its
+// package file for reading. The caller must Close the reader.
+func Open(dir string) (*Reader, error) {
+	products, err := loadProducts(filepath.Join(dir, ProductFile))
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(dir, PackageFile))
+	if err != nil {
+		return nil, err
+	}
+
+	packages, err := newTable(f, PackageFile, packageColumns)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &Reader{products: products, packages: packages, file: f}, nil
+}
+
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+func (r *Reader) Next() (domain.ImportRow, error) {
+	rec, err := r.packages.next()
+	if err != nil {
+		return domain.ImportRow{}, err
+	}
+
+	row := domain.ImportRow{Line: rec.line}
+	if rec.err != nil {
+		row.Err = rec.err
+		return row, nil
+	}
+
+	productID := rec.field("PRODUCTID")
+	med, ok := r.products[productID]
+	if !ok {
+		row.Medicine.NDC = rec.field("NDCPACKAGECODE")
+		row.Err = fmt.Errorf("unknown product %q", productID)
+		return row, nil
+	}
+
+	med.NDC = rec.field("NDCPACKAGECODE")
+	med.Ingredients = append([]domain.MedicineIngredient(nil), med.Ingredients...)
+	row.Medicine = med
+
+	return row, nil
+}
+
+func loadProducts(path string) (map[string]domain.Medicine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	products, err := newTable(f, ProductFile, productColumns)
+	if err != nil {
+		return nil, err
+	}
+
+	medicines := make(map[string]domain.Medicine)
+	for {
+		rec, err := products.next()
+		if errors.Is(err, io.EOF) {
+			return medicines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// packages of a malformed product are reported as unknown
+		if rec.err == nil {
+			medicines[rec.field("PRODUCTID")] = productMedicine(rec)
+		}
+	}
+}
+
+// productMedicine maps a product record onto the catalog fields. The
+// dosage is only set for single-ingredient products; combinations keep
+// their strengths on the ingredients.
+func productMedicine(rec record) domain.Medicine {
+	name := strings.TrimSpace(rec.field("PROPRIETARYNAME") + " " + rec.field("PROPRIETARYNAMESUFFIX"))
+	if name == "" {
+		name = rec.field("NONPROPRIETARYNAME")
+	}
+
+	med := domain.Medicine{
+		Name:          name,
+		Form:          rec.field("DOSAGEFORMNAME"),
+		PharmaCompany: rec.field("LABELERNAME"),
+	}
+
+	substances := splitList(rec.field("SUBSTANCENAME"))
+	amounts := splitList(rec.field("ACTIVE_NUMERATOR_STRENGTH"))
+	units := splitList(rec.field("ACTIVE_INGRED_UNIT"))
+
+	for i, substance := range substances {
+		in := domain.MedicineIngredient{Name: strings.ToLower(substance)}
+		if i < len(amounts) && i < len(units) {
+			in.Strength = parseStrength(amounts[i], units[i])
+		}
+		med.Ingredients = append(med.Ingredients, in)
+	}
+
+	if len(med.Ingredients) == 1 && med.Ingredients[0].Strength != nil {
+		med.Dosage = med.Ingredients[0].Strength.String()
+	}
+
+	return med
+}
+
+// parseStrength reads a numerator strength with its directory unit, such as
+// "875" with "mg/1" or "250" with "mg/5mL". Units the catalog cannot
+// express, like "g/100g", leave the strength unknown.
+func parseStrength(amount, unit string) *strength.Strength {
+	unit = strings.TrimSuffix(unit, "/1")
+	unit = strings.ReplaceAll(unit, "[iU]", "IU")
+
+	st, err := strength.Parse(amount + " " + unit)
+	if err != nil {
+		return nil
+	}
+	return &st
+}
+
+// splitList splits the "; "-separated multi-value fields of the directory.
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// table reads a tab-delimited file with a header line. The directory files
+// do not quote fields, so they are split on tabs as they are.
+type table struct {
+	name    string
+	scanner *bufio.Scanner
+	columns map[string]int
+	line    int
+}
+
+type record struct {
+	line    int
+	fields  []string
+	columns map[string]int
+	err     error
+}
+
+func (r record) field(name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(r.fields) {
+		return ""
+	}
+	return strings.TrimSpace(r.fields[i])
+}
+
+func newTable(src io.Reader, name string, required []string) (*table, error) {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	t := &table{name: name, scanner: scanner}
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s header: %w", name, err)
+		}
+		return nil, fmt.Errorf("%s is empty", name)
+	}
+	t.line++
+
+	header := strings.Split(strings.TrimPrefix(scanner.Text(), "\ufeff"), "\t")
+	t.columns = make(map[string]int, len(header))
+	for i, column := range header {
+		t.columns[strings.ToUpper(strings.TrimSpace(column))] = i
+	}
+
+	for _, column := range required {
+		if _, ok := t.columns[column]; !ok {
+			return nil, fmt.Errorf("%s header must contain %q column", name, column)
+		}
+	}
+
+	return t, nil
+}
+
+func (t *table) next() (record, error) {
+	for t.scanner.Scan() {
+		t.line++
+
+		text := strings.TrimRight(t.scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		rec := record{line: t.line, fields: strings.Split(text, "\t"), columns: t.columns}
+		if len(rec.fields) < len(t.columns) {
+			rec.err = fmt.Errorf("%s: expected %d fields, got %d", t.name, len(t.columns), len(rec.fields))
+		}
+		return rec, nil
+	}
+
+	if err := t.scanner.Err(); err != nil {
+		return record{}, fmt.Errorf("failed to read %s: %w", t.name, err)
+	}
+
+	return record{}, io.EOF
+}
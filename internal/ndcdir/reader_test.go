@@ -0,0 +1,205 @@
+package ndcdir
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"hippo/internal/domain"
+	"hippo/pkg/ndc"
+)
+
+func readAll(t *testing.T) []domain.ImportRow {
+	t.Helper()
+
+	r, err := Open("testdata")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+
+	var rows []domain.ImportRow
+	for {
+		row, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func rowsByNDC(rows []domain.ImportRow) map[string][]domain.ImportRow {
+	byNDC := make(map[string][]domain.ImportRow)
+	for _, row := range rows {
+		byNDC[row.Medicine.NDC] = append(byNDC[row.Medicine.NDC], row)
+	}
+	return byNDC
+}
+
+func TestReaderRows(t *testing.T) {
+	rows := readAll(t)
+	if len(rows) != 9 {
+		t.Fatalf("got %d rows, want 9", len(rows))
+	}
+
+	for i, row := range rows {
+		if want := i + 2; row.Line != want {
+			t.Errorf("row %d: line = %d, want %d", i, row.Line, want)
+		}
+	}
+}
+
+func TestReaderMapping(t *testing.T) {
+	type ingredient struct {
+		name     string
+		strength string
+	}
+
+	tests := []struct {
+		ndc         string
+		name        string
+		form        string
+		company     string
+		dosage      string
+		ingredients []ingredient
+	}{
+		{
+			ndc:     "0093-2264-01",
+			name:    "Amoxicillin and Clavulanate Potassium",
+			form:    "TABLET, FILM COATED",
+			company: "Teva Pharmaceuticals USA, Inc.",
+			dosage:  "",
+			ingredients: []ingredient{
+				{"amoxicillin", "875 mg"},
+				{"clavulanate potassium", "125 mg"},
+			},
+		},
+		{
+			ndc:         "0781-6156-46",
+			name:        "Amoxicillin",
+			form:        "POWDER, FOR SUSPENSION",
+			company:     "Sandoz Inc",
+			dosage:      "250 mg/5 mL",
+			ingredients: []ingredient{{"amoxicillin", "250 mg/5 mL"}},
+		},
+		{
+			ndc:         "0168-0055-30",
+			name:        "Hydrocortisone",
+			form:        "CREAM",
+			company:     "E. Fougera & Co. a division of Fougera Pharmaceuticals Inc.",
+			dosage:      "",
+			ingredients: []ingredient{{"hydrocortisone", ""}},
+		},
+	}
+
+	byNDC := rowsByNDC(readAll(t))
+
+	for _, tt := range tests {
+		t.Run(tt.ndc, func(t *testing.T) {
+			found := byNDC[tt.ndc]
+			if len(found) != 1 {
+				t.Fatalf("got %d rows, want 1", len(found))
+			}
+
+			row := found[0]
+			if row.Err != nil {
+				t.Fatalf("row error: %v", row.Err)
+			}
+
+			med := row.Medicine
+			if med.Name != tt.name {
+				t.Errorf("name = %q, want %q", med.Name, tt.name)
+			}
+			if med.Form != tt.form {
+				t.Errorf("form = %q, want %q", med.Form, tt.form)
+			}
+			if med.PharmaCompany != tt.company {
+				t.Errorf("pharma company = %q, want %q", med.PharmaCompany, tt.company)
+			}
+			if med.Dosage != tt.dosage {
+				t.Errorf("dosage = %q, want %q", med.Dosage, tt.dosage)
+			}
+
+			if len(med.Ingredients) != len(tt.ingredients) {
+				t.Fatalf("got %d ingredients, want %d", len(med.Ingredients), len(tt.ingredients))
+			}
+			for i, want := range tt.ingredients {
+				got := med.Ingredients[i]
+				if got.Name != want.name {
+					t.Errorf("ingredient %d: name = %q, want %q", i, got.Name, want.name)
+				}
+
+				var st string
+				if got.Strength != nil {
+					st = got.Strength.String()
+				}
+				if st != want.strength {
+					t.Errorf("ingredient %d: strength = %q, want %q", i, st, want.strength)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderUnknownProduct(t *testing.T) {
+	found := rowsByNDC(readAll(t))["0000-0000-00"]
+	if len(found) != 1 {
+		t.Fatalf("got %d rows, want 1", len(found))
+	}
+
+	row := found[0]
+	if row.Err == nil || !strings.Contains(row.Err.Error(), "unknown product") {
+		t.Errorf("error = %v, want unknown product", row.Err)
+	}
+	if row.Line != 9 {
+		t.Errorf("line = %d, want 9", row.Line)
+	}
+}
+
+func TestReaderDuplicatePackage(t *testing.T) {
+	found := rowsByNDC(readAll(t))["0904-5853-60"]
+	if len(found) != 2 {
+		t.Fatalf("got %d rows, want 2", len(found))
+	}
+
+	for _, row := range found {
+		if row.Err != nil {
+			t.Errorf("line %d: error = %v", row.Line, row.Err)
+		}
+		if row.Medicine.Name != "Ibuprofen" || row.Medicine.Dosage != "200 mg" {
+			t.Errorf("line %d: got %q %q, want Ibuprofen 200 mg", row.Line, row.Medicine.Name, row.Medicine.Dosage)
+		}
+	}
+
+	if found[0].Line == found[1].Line {
+		t.Errorf("both rows report line %d", found[0].Line)
+	}
+}
+
+func TestReaderShortPackageCode(t *testing.T) {
+	found := rowsByNDC(readAll(t))["50090-3155-0"]
+	if len(found) != 1 {
+		t.Fatalf("got %d rows, want 1", len(found))
+	}
+
+	row := found[0]
+	if row.Err != nil {
+		t.Fatalf("row error: %v", row.Err)
+	}
+	if row.Medicine.Name != "Lisinopril" || row.Medicine.Dosage != "10 mg" {
+		t.Errorf("got %q %q, want Lisinopril 10 mg", row.Medicine.Name, row.Medicine.Dosage)
+	}
+
+	// the 5-4-1 layout is stored padded to 5-4-2
+	code, err := ndc.Normalize(row.Medicine.NDC)
+	if err != nil {
+		t.Fatalf("Normalize(%q): %v", row.Medicine.NDC, err)
+	}
+	if code != "50090-3155-00" {
+		t.Errorf("Normalize(%q) = %q, want %q", row.Medicine.NDC, code, "50090-3155-00")
+	}
+}
@@ -18,12 +18,25 @@ type Config struct {
 	HttpServer HttpServer      `mapstructure:"http_server" validate:"required"`
 	GrpcAudit  GrpcAuditClient `mapstructure:"grpc_audit_client" validate:"required"`
 	DBConn     DBConn          `mapstructure:"db_conn" validate:"required"`
+	Expiry     ExpiryWorker    `mapstructure:"expiry_worker"`
 }
 
 type App struct {
 	HandlerTimeout   time.Duration `mapstructure:"handler_timeout" validate:"required,gt=0"`
+	BulkTimeout      time.Duration `mapstructure:"bulk_timeout" validate:"required,gt=0"`
 	RefreshTokenLife time.Duration `mapstructure:"refresh_token_life" validate:"required,gt=0"`
 	AccessTokenLife  time.Duration `mapstructure:"access_token_life" validate:"required,gt=0"`
+	DeletedRetention time.Duration `mapstructure:"deleted_retention" validate:"required,gt=0"`
+	// AdminUserIDs are granted the admin role on startup
+	AdminUserIDs      []int64 `mapstructure:"admin_user_ids" validate:"dive,gt=0"`
+	StrictDosageForms bool    `mapstructure:"strict_dosage_forms"`
+}
+
+// ExpiryWorker configures the background job that quarantines expired lots.
+type ExpiryWorker struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Interval   time.Duration `mapstructure:"interval" validate:"required,gt=0"`
+	WarnWithin time.Duration `mapstructure:"warn_within" validate:"required,gt=0"`
 }
 
 type HttpServer struct {
@@ -110,8 +123,14 @@ func NewConfig(configDir, configName string) (*Config, error) {
 
 func setDefaults(v *viper.Viper) {
 	v.SetDefault("app.handler_timeout", 3*time.Second)
+	v.SetDefault("app.bulk_timeout", 2*time.Minute)
 	v.SetDefault("app.refresh_token_life", 720*time.Minute)
 	v.SetDefault("app.access_token_life", 3*time.Minute)
+	v.SetDefault("app.deleted_retention", 720*time.Hour)
+
+	v.SetDefault("expiry_worker.enabled", true)
+	v.SetDefault("expiry_worker.interval", time.Hour)
+	v.SetDefault("expiry_worker.warn_within", 720*time.Hour)
 
 	v.SetDefault("http_server.port", 8080)
 	v.SetDefault("http_server.read_timeout", 5*time.Second)
@@ -22,6 +22,10 @@ func Int(key string, value int) Field {
 	return Field{Key: key, Value: value}
 }
 
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
 func Err(err error) Field {
 	return Field{
 		Key:   "error",
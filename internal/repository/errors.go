@@ -67,3 +67,196 @@ func NewErrTokenNotFound() error {
 func (e *ErrTokenNotFound) Error() string {
 	return fmt.Sprintf("invalid credential")
 }
+
+type ErrDuplicateNDC struct {
+	NDC        string
+	ExistingID int64
+}
+
+func NewErrDuplicateNDC(ndc string, existingID int64) error {
+	return &ErrDuplicateNDC{
+		NDC:        ndc,
+		ExistingID: existingID,
+	}
+}
+
+func (e *ErrDuplicateNDC) Error() string {
+	return fmt.Sprintf("duplicated ndc %s: already used by medicine with ID %d", e.NDC, e.ExistingID)
+}
+
+type ErrVersionMismatch struct {
+	Op       string
+	Entity   string
+	ID       interface{}
+	Expected int
+	Current  int
+}
+
+func NewErrVersionMismatch(op, entity string, id interface{}, expected, current int) error {
+	return &ErrVersionMismatch{
+		Op:       op,
+		Entity:   entity,
+		ID:       id,
+		Expected: expected,
+		Current:  current,
+	}
+}
+
+func (e *ErrVersionMismatch) Error() string {
+	return fmt.Sprintf("%s: %s with ID %v has version %d, expected %d", e.Op, e.Entity, e.ID, e.Current, e.Expected)
+}
+
+type ErrInsufficientStock struct {
+	MedicineID int64
+	Location   string
+	OnHand     int
+	Requested  int
+}
+
+func NewErrInsufficientStock(medicineID int64, location string, onHand, requested int) error {
+	return &ErrInsufficientStock{
+		MedicineID: medicineID,
+		Location:   location,
+		OnHand:     onHand,
+		Requested:  requested,
+	}
+}
+
+func (e *ErrInsufficientStock) Error() string {
+	return fmt.Sprintf("insufficient stock of medicine %d at %s: %d on hand, %d requested",
+		e.MedicineID, e.Location, e.OnHand, e.Requested)
+}
+
+type ErrDuplicateLot struct {
+	MedicineID int64
+	LotNumber  string
+}
+
+func NewErrDuplicateLot(medicineID int64, lotNumber string) error {
+	return &ErrDuplicateLot{
+		MedicineID: medicineID,
+		LotNumber:  lotNumber,
+	}
+}
+
+func (e *ErrDuplicateLot) Error() string {
+	return fmt.Sprintf("duplicated lot %s for medicine %d", e.LotNumber, e.MedicineID)
+}
+
+type ErrLotInUse struct {
+	LotID int64
+}
+
+func NewErrLotInUse(lotID int64) error {
+	return &ErrLotInUse{LotID: lotID}
+}
+
+func (e *ErrLotInUse) Error() string {
+	return fmt.Sprintf("lot %d has stock movements", e.LotID)
+}
+
+type ErrDuplicateInteraction struct {
+	IngredientA string
+	IngredientB string
+}
+
+func NewErrDuplicateInteraction(ingredientA, ingredientB string) error {
+	return &ErrDuplicateInteraction{
+		IngredientA: ingredientA,
+		IngredientB: ingredientB,
+	}
+}
+
+func (e *ErrDuplicateInteraction) Error() string {
+	return fmt.Sprintf("duplicated interaction between %s and %s", e.IngredientA, e.IngredientB)
+}
+
+type ErrDuplicateDosageForm struct {
+	Term string
+}
+
+func NewErrDuplicateDosageForm(term string) error {
+	return &ErrDuplicateDosageForm{Term: term}
+}
+
+func (e *ErrDuplicateDosageForm) Error() string {
+	return fmt.Sprintf("dosage form code or synonym %q already exists", e.Term)
+}
+
+type ErrDosageFormInUse struct {
+	Code      string
+	Medicines int
+}
+
+func NewErrDosageFormInUse(code string, medicines int) error {
+	return &ErrDosageFormInUse{Code: code, Medicines: medicines}
+}
+
+func (e *ErrDosageFormInUse) Error() string {
+	return fmt.Sprintf("dosage form %s is used by %d medicines", e.Code, e.Medicines)
+}
+
+type ErrDuplicateManufacturer struct {
+	Name       string
+	ExistingID int64
+}
+
+func NewErrDuplicateManufacturer(name string, existingID int64) error {
+	return &ErrDuplicateManufacturer{Name: name, ExistingID: existingID}
+}
+
+func (e *ErrDuplicateManufacturer) Error() string {
+	return fmt.Sprintf("duplicated manufacturer %q, existing id %d", e.Name, e.ExistingID)
+}
+
+type ErrManufacturerInUse struct {
+	ID int64
+}
+
+func NewErrManufacturerInUse(id int64) error {
+	return &ErrManufacturerInUse{ID: id}
+}
+
+func (e *ErrManufacturerInUse) Error() string {
+	return fmt.Sprintf("manufacturer %d is referenced by medicines", e.ID)
+}
+
+type ErrDuplicateCategory struct {
+	Code       string
+	ExistingID int64
+}
+
+func NewErrDuplicateCategory(code string, existingID int64) error {
+	return &ErrDuplicateCategory{Code: code, ExistingID: existingID}
+}
+
+func (e *ErrDuplicateCategory) Error() string {
+	return fmt.Sprintf("duplicated category code %q, existing id %d", e.Code, e.ExistingID)
+}
+
+type ErrCategoryInUse struct {
+	ID        int64
+	Children  int
+	Medicines int
+}
+
+func NewErrCategoryInUse(id int64, children, medicines int) error {
+	return &ErrCategoryInUse{ID: id, Children: children, Medicines: medicines}
+}
+
+func (e *ErrCategoryInUse) Error() string {
+	return fmt.Sprintf("category %d has %d subcategories and %d medicines", e.ID, e.Children, e.Medicines)
+}
+
+type ErrCategoryCycle struct {
+	ID       int64
+	ParentID int64
+}
+
+func NewErrCategoryCycle(id, parentID int64) error {
+	return &ErrCategoryCycle{ID: id, ParentID: parentID}
+}
+
+func (e *ErrCategoryCycle) Error() string {
+	return fmt.Sprintf("category %d cannot be moved under its own subtree category %d", e.ID, e.ParentID)
+}
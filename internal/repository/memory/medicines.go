@@ -0,0 +1,96 @@
+// Package memory holds in-process repository implementations that follow
+// the semantics of their psql counterparts.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"hippo/internal/domain"
+	"hippo/pkg/trigram"
+)
+
+// MedicineSearch ranks medicines the same way psql.Medicines.Search does:
+// a row matches when every query word occurs in it or when any of name,
+// active ingredient and pharma company reaches the trigram word-similarity
+// threshold. The score is the best word similarity over those fields.
+type MedicineSearch struct {
+	mu        sync.RWMutex
+	medicines []domain.Medicine
+}
+
+func NewMedicineSearch(medicines []domain.Medicine) *MedicineSearch {
+	return &MedicineSearch{
+		medicines: medicines,
+	}
+}
+
+func (s *MedicineSearch) Add(medicines ...domain.Medicine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.medicines = append(s.medicines, medicines...)
+}
+
+func (s *MedicineSearch) Search(ctx context.Context, query string, limit int) ([]domain.MedicineSearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make([]domain.MedicineSearchResult, 0)
+	for _, med := range s.medicines {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		fields := []string{med.Name, med.ActiveIngredient, med.PharmaCompany}
+
+		var score float64
+		for _, f := range fields {
+			if sim := trigram.WordSimilarity(query, f); sim > score {
+				score = sim
+			}
+		}
+
+		if score < trigram.Threshold && !containsAllWords(query, fields) {
+			continue
+		}
+
+		results = append(results, domain.MedicineSearchResult{Medicine: med, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+func containsAllWords(query string, fields []string) bool {
+	words := make(map[string]struct{})
+	for _, f := range fields {
+		for _, w := range trigram.Words(f) {
+			words[w] = struct{}{}
+		}
+	}
+
+	qWords := trigram.Words(query)
+	if len(qWords) == 0 {
+		return false
+	}
+
+	for _, w := range qWords {
+		if _, ok := words[w]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
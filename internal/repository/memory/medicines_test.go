@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"hippo/internal/domain"
+)
+
+var testMedicines = []domain.Medicine{
+	{ID: 1, Name: "Acetaminophen", ActiveIngredient: "acetaminophen", PharmaCompany: "Johnson & Johnson"},
+	{ID: 2, Name: "Ibuprom", PharmaCompany: "US Pharmacia"},
+	{ID: 3, Name: "Ibuprofen", ActiveIngredient: "ibuprofen", PharmaCompany: "Pfizer"},
+	{ID: 4, Name: "Amoxicillin", ActiveIngredient: "amoxicillin trihydrate", PharmaCompany: "Teva"},
+	{ID: 5, Name: "Ibrutinib", ActiveIngredient: "ibrutinib", PharmaCompany: "Pharmacyclics"},
+	{ID: 6, Name: "Advil", ActiveIngredient: "ibuprofen", PharmaCompany: "Pfizer"},
+}
+
+func TestMedicineSearch(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []int
+	}{
+		{
+			name:  "exact matches rank above partial, ties by id",
+			query: "ibuprofen",
+			want:  []int{3, 6, 2},
+		},
+		{
+			name:  "typo ranks closest name first",
+			query: "ibuprofn",
+			want:  []int{3, 6, 2},
+		},
+		{
+			name:  "typo in ingredient",
+			query: "amoxicilin",
+			want:  []int{4},
+		},
+		{
+			name:  "all words across fields",
+			query: "advil pfizer",
+			want:  []int{6},
+		},
+		{
+			name:  "limit",
+			query: "ibuprofen",
+			limit: 2,
+			want:  []int{3, 6},
+		},
+		{
+			name:  "no match",
+			query: "zolpidem",
+			want:  []int{},
+		},
+	}
+
+	search := NewMedicineSearch(testMedicines)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := search.Search(context.Background(), tt.query, tt.limit)
+			if err != nil {
+				t.Fatalf("Search(%q) error: %v", tt.query, err)
+			}
+
+			got := make([]int, 0, len(results))
+			for _, r := range results {
+				got = append(got, r.ID)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMedicineSearchScoresDescending(t *testing.T) {
+	results, err := NewMedicineSearch(testMedicines).Search(context.Background(), "ibuprofn", 0)
+	if err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+
+	for i := 1; i < len(results); i++ {
+		if results[i].Score > results[i-1].Score {
+			t.Errorf("result %d scores %v above result %d at %v", i, results[i].Score, i-1, results[i-1].Score)
+		}
+	}
+}
+
+func TestMedicineSearchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewMedicineSearch(testMedicines).Search(ctx, "ibuprofen", 0); err == nil {
+		t.Error("Search with canceled context returned no error")
+	}
+}
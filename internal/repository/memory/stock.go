@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+// Stock keeps a stock ledger with the balance rules of psql.Stock.Record:
+// no location and no lot at a location, including the stock held without a
+// lot, may go negative. Every medicine id is taken to exist.
+type Stock struct {
+	mu        sync.Mutex
+	lots      map[int64]domain.Lot
+	movements []domain.StockMovement
+}
+
+func NewStock(lots ...domain.Lot) *Stock {
+	s := &Stock{
+		lots: make(map[int64]domain.Lot, len(lots)),
+	}
+	for _, lot := range lots {
+		s.lots[lot.ID] = lot
+	}
+	return s
+}
+
+// SetLot adds or replaces a lot, e.g. to quarantine it.
+func (s *Stock) SetLot(lot domain.Lot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lots[lot.ID] = lot
+}
+
+// Record appends movements all or nothing, checking each against the ledger
+// including the movements before it in the same call.
+func (s *Stock) Record(ctx context.Context, medicineID int64, movements []domain.StockMovement) ([]domain.StockMovement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger := append([]domain.StockMovement(nil), s.movements...)
+	recorded := make([]domain.StockMovement, 0, len(movements))
+	for _, mv := range movements {
+		if mv.Quantity < 0 {
+			onHand := sumStock(ledger, medicineID, mv.Location, func(domain.StockMovement) bool { return true })
+			if onHand+mv.Quantity < 0 {
+				return nil, repository.NewErrInsufficientStock(medicineID, mv.Location, onHand, -mv.Quantity)
+			}
+
+			lotOnHand := sumStock(ledger, medicineID, mv.Location, func(other domain.StockMovement) bool {
+				return sameLot(other.LotID, mv.LotID)
+			})
+			if lotOnHand+mv.Quantity < 0 {
+				return nil, repository.NewErrInsufficientStock(medicineID, mv.Location, lotOnHand, -mv.Quantity)
+			}
+		}
+
+		mv.ID = int64(len(ledger) + 1)
+		mv.MedicineID = medicineID
+		ledger = append(ledger, mv)
+		recorded = append(recorded, mv)
+	}
+
+	s.movements = ledger
+
+	return recorded, nil
+}
+
+func (s *Stock) Levels(ctx context.Context, medicineID int64) ([]domain.StockItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byLocation := make(map[string]int)
+	for _, mv := range s.movements {
+		if mv.MedicineID == medicineID {
+			byLocation[mv.Location] += mv.Quantity
+		}
+	}
+
+	items := make([]domain.StockItem, 0, len(byLocation))
+	for location, onHand := range byLocation {
+		items = append(items, domain.StockItem{MedicineID: medicineID, Location: location, OnHand: onHand})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Location < items[j].Location
+	})
+
+	return items, nil
+}
+
+func (s *Stock) Movements(ctx context.Context, medicineID int64) ([]domain.StockMovement, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	movements := make([]domain.StockMovement, 0)
+	for _, mv := range s.movements {
+		if mv.MedicineID == medicineID {
+			movements = append(movements, mv)
+		}
+	}
+
+	return movements, nil
+}
+
+// LotBalances returns positive balances of active lots not expired on day
+// now, first-expiring first.
+func (s *Stock) LotBalances(ctx context.Context, medicineID int64, location string, now time.Time) ([]domain.LotBalance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byLot := make(map[int64]int)
+	for _, mv := range s.movements {
+		if mv.MedicineID == medicineID && mv.Location == location && mv.LotID != nil {
+			byLot[*mv.LotID] += mv.Quantity
+		}
+	}
+
+	balances := make([]domain.LotBalance, 0)
+	for id, onHand := range byLot {
+		lot, ok := s.lots[id]
+		if !ok || onHand <= 0 || lot.Status != domain.LotActive || lot.Expired(now) {
+			continue
+		}
+		balances = append(balances, domain.LotBalance{LotID: id, ExpiresAt: lot.ExpiresAt, OnHand: onHand})
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		if !balances[i].ExpiresAt.Equal(balances[j].ExpiresAt.Time) {
+			return balances[i].ExpiresAt.Before(balances[j].ExpiresAt.Time)
+		}
+		return balances[i].LotID < balances[j].LotID
+	})
+
+	return balances, nil
+}
+
+func sumStock(ledger []domain.StockMovement, medicineID int64, location string, match func(domain.StockMovement) bool) int {
+	var onHand int
+	for _, mv := range ledger {
+		if mv.MedicineID == medicineID && mv.Location == location && match(mv) {
+			onHand += mv.Quantity
+		}
+	}
+	return onHand
+}
+
+func sameLot(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,371 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+const (
+	categoriesCodeKey = "categories_code_key"
+
+	categoryColumns = "id, code, name, parent_id, created_at, updated_at"
+
+	// medicineCategoriesColumn lists the category codes of the medicine row in
+	// scope, see medicineIngredientsColumn.
+	medicineCategoriesColumn = `(
+		SELECT coalesce(array_agg(c.code ORDER BY c.code), '{}')
+		FROM medicine_categories mc
+		JOIN categories c ON c.id = mc.category_id
+		WHERE mc.medicine_id = medicines.id
+	) AS categories`
+)
+
+type Categories struct {
+	db *sql.DB
+}
+
+func NewCategories(db *sql.DB) *Categories {
+	return &Categories{
+		db: db,
+	}
+}
+
+func (c *Categories) Create(ctx context.Context, category domain.Category) (int64, error) {
+	const op = "repository.psql.categories.Create"
+	const query = `
+		INSERT INTO categories (code, name, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING id
+	`
+
+	var id int64
+	err := c.db.QueryRowContext(ctx, query,
+		category.Code,
+		category.Name,
+		sqlNullInt64(category.ParentID),
+		category.CreatedAt,
+	).Scan(&id)
+	if isUniqueViolation(err, categoriesCodeKey) {
+		return 0, c.duplicate(ctx, op, category.Code)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to create category: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// duplicate builds ErrDuplicateCategory pointing at the row that owns code.
+func (c *Categories) duplicate(ctx context.Context, op, code string) error {
+	var existingID int64
+	if err := c.db.QueryRowContext(ctx, "SELECT id FROM categories WHERE code = $1", code).Scan(&existingID); err != nil {
+		return fmt.Errorf("%s: failed to get category with duplicated code: %w", op, err)
+	}
+
+	return repository.NewErrDuplicateCategory(code, existingID)
+}
+
+func (c *Categories) GetByID(ctx context.Context, id int64) (domain.Category, error) {
+	const op = "repository.psql.categories.GetByID"
+
+	category, err := scanCategory(c.db.QueryRowContext(ctx,
+		"SELECT "+categoryColumns+" FROM categories WHERE id = $1", id))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.Category{}, repository.NewNotFoundError(op, "category", id)
+	case err != nil:
+		return domain.Category{}, fmt.Errorf("%s: failed to get category by id: %w", op, err)
+	}
+
+	return category, nil
+}
+
+// List returns every category ordered by code, which keeps ATC subgroups
+// right after their parents.
+func (c *Categories) List(ctx context.Context) ([]domain.Category, error) {
+	const op = "repository.psql.categories.List"
+
+	rows, err := c.db.QueryContext(ctx, "SELECT "+categoryColumns+" FROM categories ORDER BY code")
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get categories: %w", op, err)
+	}
+	defer rows.Close()
+
+	categories := make([]domain.Category, 0)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan category row: %w", op, err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return categories, nil
+}
+
+// Codes returns the ids of those codes that name a category.
+func (c *Categories) Codes(ctx context.Context, codes []string) (map[string]int64, error) {
+	const op = "repository.psql.categories.Codes"
+
+	rows, err := c.db.QueryContext(ctx, "SELECT code, id FROM categories WHERE code = ANY($1)", pq.Array(codes))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get categories: %w", op, err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int64, len(codes))
+	for rows.Next() {
+		var (
+			code string
+			id   int64
+		)
+		if err = rows.Scan(&code, &id); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan category row: %w", op, err)
+		}
+		ids[code] = id
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+// Update changes a category. Moving it is refused with ErrCategoryCycle when
+// the new parent lies in its own subtree. A new code shows on the medicines
+// filed under the category, so they get a new version and rev is recorded
+// for each of them.
+func (c *Categories) Update(ctx context.Context, id int64, upd domain.UpdateCategory, rev domain.MedicineRevision) error {
+	const op = "repository.psql.categories.Update"
+	var (
+		setValues []string
+		args      []interface{}
+		argID     = 1
+	)
+
+	if upd.Code != nil {
+		setValues = append(setValues, fmt.Sprintf("code = $%d", argID))
+		args = append(args, *upd.Code)
+		argID += 1
+	}
+
+	if upd.Name != nil {
+		setValues = append(setValues, fmt.Sprintf("name = $%d", argID))
+		args = append(args, *upd.Name)
+		argID += 1
+	}
+
+	if upd.ParentID != nil {
+		setValues = append(setValues, fmt.Sprintf("parent_id = $%d", argID))
+		args = append(args, sql.NullInt64{Int64: *upd.ParentID, Valid: *upd.ParentID > 0})
+		argID += 1
+	}
+
+	if len(setValues) == 0 {
+		return repository.NewErrEmptyUpdate(op, "category")
+	}
+
+	setValues = append(setValues, fmt.Sprintf("updated_at = $%d", argID))
+	args = append(args, time.Now(), id)
+	query := fmt.Sprintf("UPDATE categories SET %s WHERE id = $%d", strings.Join(setValues, ", "), argID+1)
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if upd.ParentID != nil && *upd.ParentID > 0 {
+		// concurrent moves could each pass the check and close a loop together
+		if _, err = tx.ExecContext(ctx, "LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+			return fmt.Errorf("%s: failed to lock categories: %w", op, err)
+		}
+
+		var cycle bool
+		err = tx.QueryRowContext(ctx, `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM categories WHERE id = $1
+				UNION
+				SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`,
+			*upd.ParentID, id,
+		).Scan(&cycle)
+		if err != nil {
+			return fmt.Errorf("%s: failed to check category ancestors: %w", op, err)
+		}
+
+		if cycle {
+			return repository.NewErrCategoryCycle(id, *upd.ParentID)
+		}
+	}
+
+	var relabeled []domain.Medicine
+	if upd.Code != nil {
+		var code string
+		err = tx.QueryRowContext(ctx, "SELECT code FROM categories WHERE id = $1 FOR UPDATE", id).Scan(&code)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.NewNotFoundError(op, "category", id)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: failed to get category code: %w", op, err)
+		}
+
+		if code != *upd.Code {
+			relabeled, err = lockMedicines(ctx, tx,
+				"id IN (SELECT medicine_id FROM medicine_categories WHERE category_id = $1)", id,
+			)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if isUniqueViolation(err, categoriesCodeKey) {
+		return c.duplicate(ctx, op, *upd.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to update category: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "category", id)
+	}
+
+	if len(relabeled) > 0 {
+		ids := make([]int64, len(relabeled))
+		for i, medicine := range relabeled {
+			ids[i] = int64(medicine.ID)
+		}
+
+		if _, err = tx.ExecContext(ctx,
+			"UPDATE medicines SET version = version + 1 WHERE id = ANY($1)", pq.Array(ids),
+		); err != nil {
+			return fmt.Errorf("%s: failed to bump medicine versions: %w", op, err)
+		}
+
+		if err = reviseMedicines(ctx, tx, relabeled, rev); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return nil
+}
+
+// Delete removes a category that has neither subcategories nor medicines.
+// The category row is locked first, so a subcategory or medicine link added
+// concurrently either lands before the check or waits for the delete.
+func (c *Categories) Delete(ctx context.Context, id int64) error {
+	const op = "repository.psql.categories.Delete"
+
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var locked int64
+	err = tx.QueryRowContext(ctx, "SELECT id FROM categories WHERE id = $1 FOR UPDATE", id).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.NewNotFoundError(op, "category", id)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to lock category: %w", op, err)
+	}
+
+	var children, medicines int
+	err = tx.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM categories WHERE parent_id = $1),
+			(SELECT COUNT(*) FROM medicine_categories WHERE category_id = $1)`,
+		id,
+	).Scan(&children, &medicines)
+	if err != nil {
+		return fmt.Errorf("%s: failed to count category references: %w", op, err)
+	}
+
+	if children > 0 || medicines > 0 {
+		return repository.NewErrCategoryInUse(id, children, medicines)
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM categories WHERE id = $1", id); err != nil {
+		return fmt.Errorf("%s: failed to delete category: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return nil
+}
+
+// replaceMedicineCategories files a medicine under exactly the categories
+// named by codes. Codes without a category are skipped.
+func replaceMedicineCategories(ctx context.Context, tx *sql.Tx, medicineID int64, codes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM medicine_categories WHERE medicine_id = $1", medicineID); err != nil {
+		return fmt.Errorf("failed to clear medicine categories: %w", err)
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO medicine_categories (medicine_id, category_id)
+		SELECT $1, id FROM categories WHERE code = ANY($2)`,
+		medicineID, pq.Array(codes),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to link medicine categories: %w", err)
+	}
+
+	return nil
+}
+
+func scanCategory(row rowScanner) (domain.Category, error) {
+	var (
+		category domain.Category
+		parentID sql.NullInt64
+	)
+	err := row.Scan(
+		&category.ID,
+		&category.Code,
+		&category.Name,
+		&parentID,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+	)
+	if parentID.Valid {
+		category.ParentID = &parentID.Int64
+	}
+	return category, err
+}
@@ -0,0 +1,251 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+const (
+	dosageFormsPkey     = "dosage_forms_pkey"
+	dosageFormTermsPkey = "dosage_form_terms_pkey"
+
+	// every form is also a term of itself, so codes and synonyms share one
+	// uniqueness constraint
+	dosageFormSelect = `
+		SELECT f.code, f.name, f.created_at, f.updated_at,
+			COALESCE(array_agg(t.term ORDER BY t.term) FILTER (WHERE t.term <> f.code), '{}')
+		FROM dosage_forms f
+		LEFT JOIN dosage_form_terms t ON t.code = f.code
+	`
+)
+
+type DosageForms struct {
+	db *sql.DB
+}
+
+func NewDosageForms(db *sql.DB) *DosageForms {
+	return &DosageForms{
+		db: db,
+	}
+}
+
+func (d *DosageForms) Create(ctx context.Context, form domain.DosageForm) error {
+	const op = "repository.psql.dosageForms.Create"
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO dosage_forms (code, name, created_at, updated_at) VALUES ($1, $2, $3, $3)",
+		form.Code, form.Name, form.CreatedAt,
+	)
+	if isUniqueViolation(err, dosageFormsPkey) {
+		return repository.NewErrDuplicateDosageForm(form.Code)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to create dosage form: %w", op, err)
+	}
+
+	if err = insertFormTerms(ctx, tx, form.Code, append([]string{form.Code}, form.Synonyms...)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return nil
+}
+
+func (d *DosageForms) Get(ctx context.Context, code string) (domain.DosageForm, error) {
+	const op = "repository.psql.dosageForms.Get"
+
+	form, err := scanDosageForm(d.db.QueryRowContext(ctx,
+		dosageFormSelect+" WHERE f.code = $1 GROUP BY f.code", code))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.DosageForm{}, repository.NewNotFoundError(op, "dosage form", code)
+	case err != nil:
+		return domain.DosageForm{}, fmt.Errorf("%s: failed to get dosage form: %w", op, err)
+	}
+
+	return form, nil
+}
+
+func (d *DosageForms) List(ctx context.Context) ([]domain.DosageForm, error) {
+	const op = "repository.psql.dosageForms.List"
+
+	rows, err := d.db.QueryContext(ctx, dosageFormSelect+" GROUP BY f.code ORDER BY f.code")
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get dosage forms: %w", op, err)
+	}
+	defer rows.Close()
+
+	forms := make([]domain.DosageForm, 0)
+	for rows.Next() {
+		form, err := scanDosageForm(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan dosage form row: %w", op, err)
+		}
+		forms = append(forms, form)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return forms, nil
+}
+
+// Terms maps every code and synonym to its canonical code.
+func (d *DosageForms) Terms(ctx context.Context) (map[string]string, error) {
+	const op = "repository.psql.dosageForms.Terms"
+
+	rows, err := d.db.QueryContext(ctx, "SELECT term, code FROM dosage_form_terms")
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get dosage form terms: %w", op, err)
+	}
+	defer rows.Close()
+
+	terms := make(map[string]string)
+	for rows.Next() {
+		var term, code string
+		if err = rows.Scan(&term, &code); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan dosage form term: %w", op, err)
+		}
+		terms[term] = code
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return terms, nil
+}
+
+// Update renames a form and, when synonyms are given, replaces them.
+func (d *DosageForms) Update(ctx context.Context, code string, upd domain.UpdateDosageForm) error {
+	const op = "repository.psql.dosageForms.Update"
+
+	if upd.Name == nil && upd.Synonyms == nil {
+		return repository.NewErrEmptyUpdate(op, "dosage form")
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE dosage_forms SET name = COALESCE($1, name), updated_at = $2 WHERE code = $3",
+		upd.Name, time.Now(), code,
+	)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update dosage form: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "dosage form", code)
+	}
+
+	if upd.Synonyms != nil {
+		if _, err = tx.ExecContext(ctx,
+			"DELETE FROM dosage_form_terms WHERE code = $1 AND term <> $1", code,
+		); err != nil {
+			return fmt.Errorf("%s: failed to clear synonyms: %w", op, err)
+		}
+
+		if err = insertFormTerms(ctx, tx, code, *upd.Synonyms); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return nil
+}
+
+// Delete removes a form that no medicine uses, tombstoned ones included.
+func (d *DosageForms) Delete(ctx context.Context, code string) error {
+	const op = "repository.psql.dosageForms.Delete"
+
+	var used int
+	if err := d.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM medicines WHERE form = $1", code,
+	).Scan(&used); err != nil {
+		return fmt.Errorf("%s: failed to count medicines: %w", op, err)
+	}
+
+	if used > 0 {
+		return repository.NewErrDosageFormInUse(code, used)
+	}
+
+	result, err := d.db.ExecContext(ctx, "DELETE FROM dosage_forms WHERE code = $1", code)
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete dosage form: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "dosage form", code)
+	}
+
+	return nil
+}
+
+func insertFormTerms(ctx context.Context, tx *sql.Tx, code string, terms []string) error {
+	for _, term := range terms {
+		_, err := tx.ExecContext(ctx, "INSERT INTO dosage_form_terms (term, code) VALUES ($1, $2)", term, code)
+		if isUniqueViolation(err, dosageFormTermsPkey) {
+			return repository.NewErrDuplicateDosageForm(term)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to insert dosage form term: %w", err)
+		}
+	}
+	return nil
+}
+
+func scanDosageForm(row rowScanner) (domain.DosageForm, error) {
+	var (
+		form     domain.DosageForm
+		synonyms pq.StringArray
+	)
+	err := row.Scan(
+		&form.Code,
+		&form.Name,
+		&form.CreatedAt,
+		&form.UpdatedAt,
+		&synonyms,
+	)
+	form.Synonyms = []string(synonyms)
+	return form, err
+}
@@ -0,0 +1,82 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"hippo/internal/domain"
+)
+
+// medicineIngredientsColumn aggregates the ingredients of the medicine row in
+// scope, in label order. Queries selecting medicineColumns must name that row
+// "medicines".
+const medicineIngredientsColumn = `(
+		SELECT coalesce(json_agg(json_build_object(
+			'ingredient_id', i.id, 'name', i.name, 'strength', mi.strength
+		) ORDER BY mi.position), '[]')
+		FROM medicine_ingredients mi
+		JOIN ingredients i ON i.id = mi.ingredient_id
+		WHERE mi.medicine_id = medicines.id
+	) AS ingredients`
+
+type ingredientRow struct {
+	IngredientID int64  `json:"ingredient_id"`
+	Name         string `json:"name"`
+	Strength     string `json:"strength"`
+}
+
+func decodeMedicineIngredients(raw []byte) ([]domain.MedicineIngredient, error) {
+	var rows []ingredientRow
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, err
+	}
+
+	ingredients := make([]domain.MedicineIngredient, len(rows))
+	for i, row := range rows {
+		ingredients[i] = domain.MedicineIngredient{
+			IngredientID: row.IngredientID,
+			Name:         row.Name,
+			Strength:     domain.ParseStrength(row.Strength),
+		}
+	}
+	return ingredients, nil
+}
+
+// replaceMedicineIngredients makes ingredients the complete ingredient list of
+// a medicine, adding unknown names to the catalog.
+func replaceMedicineIngredients(ctx context.Context, tx *sql.Tx, medicineID int64, ingredients []domain.MedicineIngredient) error {
+	// the no-op update makes RETURNING yield the id of an existing row too
+	const upsertIngredient = `
+		INSERT INTO ingredients (name) VALUES ($1)
+		ON CONFLICT (lower(name)) DO UPDATE SET name = ingredients.name
+		RETURNING id
+	`
+	const link = `
+		INSERT INTO medicine_ingredients (medicine_id, ingredient_id, position, strength)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM medicine_ingredients WHERE medicine_id = $1", medicineID); err != nil {
+		return fmt.Errorf("failed to clear medicine ingredients: %w", err)
+	}
+
+	for position, in := range ingredients {
+		var ingredientID int64
+		if err := tx.QueryRowContext(ctx, upsertIngredient, in.Name).Scan(&ingredientID); err != nil {
+			return fmt.Errorf("failed to upsert ingredient %q: %w", in.Name, err)
+		}
+
+		var st sql.NullString
+		if in.Strength != nil {
+			st = sql.NullString{String: in.Strength.String(), Valid: true}
+		}
+
+		if _, err := tx.ExecContext(ctx, link, medicineID, ingredientID, position, st); err != nil {
+			return fmt.Errorf("failed to link ingredient %q: %w", in.Name, err)
+		}
+	}
+
+	return nil
+}
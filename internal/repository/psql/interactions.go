@@ -0,0 +1,255 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+const (
+	interactionsPairKey = "interactions_ingredient_a_ingredient_b_key"
+
+	interactionColumns = "id, ingredient_a, ingredient_b, severity, description, created_at, updated_at"
+)
+
+type Interactions struct {
+	db *sql.DB
+}
+
+func NewInteractions(db *sql.DB) *Interactions {
+	return &Interactions{
+		db: db,
+	}
+}
+
+func (i *Interactions) Create(ctx context.Context, in domain.Interaction) (int64, error) {
+	const op = "repository.psql.interactions.Create"
+	const query = `
+		INSERT INTO interactions (ingredient_a, ingredient_b, severity, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING id
+	`
+
+	var id int64
+	err := i.db.QueryRowContext(ctx, query,
+		in.IngredientA,
+		in.IngredientB,
+		in.Severity,
+		in.Description,
+		in.CreatedAt,
+	).Scan(&id)
+
+	if isUniqueViolation(err, interactionsPairKey) {
+		return 0, repository.NewErrDuplicateInteraction(in.IngredientA, in.IngredientB)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to create interaction: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (i *Interactions) GetByID(ctx context.Context, id int64) (domain.Interaction, error) {
+	const op = "repository.psql.interactions.GetByID"
+
+	in, err := scanInteraction(i.db.QueryRowContext(ctx,
+		"SELECT "+interactionColumns+" FROM interactions WHERE id = $1", id))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.Interaction{}, repository.NewNotFoundError(op, "interaction", id)
+	case err != nil:
+		return domain.Interaction{}, fmt.Errorf("%s: failed to get interaction by id: %w", op, err)
+	}
+
+	return in, nil
+}
+
+// List returns the registry, optionally only the entries naming ingredient.
+func (i *Interactions) List(ctx context.Context, ingredient string) ([]domain.Interaction, error) {
+	const op = "repository.psql.interactions.List"
+
+	query := "SELECT " + interactionColumns + " FROM interactions"
+	var args []interface{}
+	if ingredient != "" {
+		query += " WHERE ingredient_a = $1 OR ingredient_b = $1"
+		args = append(args, ingredient)
+	}
+	query += " ORDER BY ingredient_a, ingredient_b"
+
+	return i.list(ctx, op, query, args...)
+}
+
+// FindAmong returns every entry whose two ingredients are both in the list.
+func (i *Interactions) FindAmong(ctx context.Context, ingredients []string) ([]domain.Interaction, error) {
+	const op = "repository.psql.interactions.FindAmong"
+
+	query := "SELECT " + interactionColumns + ` FROM interactions
+		WHERE ingredient_a = ANY($1) AND ingredient_b = ANY($1)
+		ORDER BY ingredient_a, ingredient_b`
+
+	return i.list(ctx, op, query, pq.Array(ingredients))
+}
+
+func (i *Interactions) list(ctx context.Context, op, query string, args ...interface{}) ([]domain.Interaction, error) {
+	rows, err := i.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get interactions: %w", op, err)
+	}
+	defer rows.Close()
+
+	interactions := make([]domain.Interaction, 0)
+	for rows.Next() {
+		in, err := scanInteraction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan interaction row: %w", op, err)
+		}
+		interactions = append(interactions, in)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return interactions, nil
+}
+
+func (i *Interactions) Update(ctx context.Context, id int64, upd domain.UpdateInteraction) error {
+	const op = "repository.psql.interactions.Update"
+	var (
+		setValues []string
+		args      []interface{}
+		argID     = 1
+	)
+
+	if upd.Severity != nil {
+		setValues = append(setValues, fmt.Sprintf("severity = $%d", argID))
+		args = append(args, *upd.Severity)
+		argID += 1
+	}
+
+	if upd.Description != nil {
+		setValues = append(setValues, fmt.Sprintf("description = $%d", argID))
+		args = append(args, *upd.Description)
+		argID += 1
+	}
+
+	if len(setValues) == 0 {
+		return repository.NewErrEmptyUpdate(op, "interaction")
+	}
+
+	setValues = append(setValues, fmt.Sprintf("updated_at = $%d", argID))
+	args = append(args, time.Now())
+	argID += 1
+
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE interactions SET %s WHERE id = $%d", strings.Join(setValues, ", "), argID)
+
+	result, err := i.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update interaction: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "interaction", id)
+	}
+
+	return nil
+}
+
+func (i *Interactions) Delete(ctx context.Context, id int64) error {
+	const op = "repository.psql.interactions.Delete"
+
+	result, err := i.db.ExecContext(ctx, "DELETE FROM interactions WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete interaction: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "interaction", id)
+	}
+
+	return nil
+}
+
+// UpsertBatch inserts or updates interactions by ingredient pair in one
+// transaction and returns their ids in input order.
+func (i *Interactions) UpsertBatch(ctx context.Context, interactions []domain.Interaction) ([]int64, error) {
+	const op = "repository.psql.interactions.UpsertBatch"
+	const query = `
+		INSERT INTO interactions (ingredient_a, ingredient_b, severity, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (ingredient_a, ingredient_b) DO UPDATE
+		SET severity = EXCLUDED.severity,
+			description = EXCLUDED.description,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id
+	`
+
+	tx, err := i.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: prepare upsert: %w", op, err)
+	}
+	defer stmt.Close()
+
+	ids := make([]int64, 0, len(interactions))
+	for _, in := range interactions {
+		var id int64
+		if err = stmt.QueryRowContext(ctx,
+			in.IngredientA,
+			in.IngredientB,
+			in.Severity,
+			in.Description,
+			in.UpdatedAt,
+		).Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: failed to upsert interaction: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+func scanInteraction(row rowScanner) (domain.Interaction, error) {
+	var in domain.Interaction
+	err := row.Scan(
+		&in.ID,
+		&in.IngredientA,
+		&in.IngredientB,
+		&in.Severity,
+		&in.Description,
+		&in.CreatedAt,
+		&in.UpdatedAt,
+	)
+	return in, err
+}
@@ -0,0 +1,221 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+const (
+	lotsNumberKey       = "lots_medicine_id_lot_number_key"
+	stockMovementsLotFK = "stock_movements_lot_id_fkey"
+
+	lotSelect = `
+		SELECT l.id, l.medicine_id, l.lot_number, l.expires_at, l.status, l.created_at,
+			COALESCE((SELECT SUM(quantity) FROM stock_movements WHERE lot_id = l.id), 0)
+		FROM lots l
+	`
+)
+
+type Lots struct {
+	db *sql.DB
+}
+
+func NewLots(db *sql.DB) *Lots {
+	return &Lots{
+		db: db,
+	}
+}
+
+func (l *Lots) Create(ctx context.Context, lot domain.Lot) (int64, error) {
+	const op = "repository.psql.lots.Create"
+	const query = `
+		INSERT INTO lots (medicine_id, lot_number, expires_at, status, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	var id int64
+	err := l.db.QueryRowContext(ctx, query,
+		lot.MedicineID,
+		lot.LotNumber,
+		lot.ExpiresAt,
+		lot.Status,
+		lot.CreatedAt,
+	).Scan(&id)
+
+	if isUniqueViolation(err, lotsNumberKey) {
+		return 0, repository.NewErrDuplicateLot(lot.MedicineID, lot.LotNumber)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to create lot: %w", op, err)
+	}
+
+	return id, nil
+}
+
+func (l *Lots) GetByID(ctx context.Context, id int64) (domain.Lot, error) {
+	const op = "repository.psql.lots.GetByID"
+
+	lot, err := scanLot(l.db.QueryRowContext(ctx, lotSelect+" WHERE l.id = $1", id))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.Lot{}, repository.NewNotFoundError(op, "lot", id)
+	case err != nil:
+		return domain.Lot{}, fmt.Errorf("%s: failed to get lot by id: %w", op, err)
+	}
+
+	return lot, nil
+}
+
+func (l *Lots) ListByMedicine(ctx context.Context, medicineID int64) ([]domain.Lot, error) {
+	const op = "repository.psql.lots.ListByMedicine"
+
+	return l.list(ctx, op, lotSelect+" WHERE l.medicine_id = $1 ORDER BY l.expires_at, l.id", medicineID)
+}
+
+// Expiring returns active lots expiring on or before the given day.
+func (l *Lots) Expiring(ctx context.Context, before domain.Date) ([]domain.Lot, error) {
+	const op = "repository.psql.lots.Expiring"
+
+	query := lotSelect + " WHERE l.status = $1 AND l.expires_at <= $2 ORDER BY l.expires_at, l.id"
+	return l.list(ctx, op, query, domain.LotActive, before)
+}
+
+func (l *Lots) list(ctx context.Context, op, query string, args ...interface{}) ([]domain.Lot, error) {
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get lots: %w", op, err)
+	}
+	defer rows.Close()
+
+	lots := make([]domain.Lot, 0)
+	for rows.Next() {
+		lot, err := scanLot(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan lot row: %w", op, err)
+		}
+		lots = append(lots, lot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return lots, nil
+}
+
+func (l *Lots) Update(ctx context.Context, id int64, upd domain.UpdateLot) error {
+	const op = "repository.psql.lots.Update"
+	var (
+		setValues []string
+		args      []interface{}
+		argID     = 1
+	)
+
+	if upd.LotNumber != nil {
+		setValues = append(setValues, fmt.Sprintf("lot_number = $%d", argID))
+		args = append(args, *upd.LotNumber)
+		argID += 1
+	}
+
+	if upd.ExpiresAt != nil {
+		setValues = append(setValues, fmt.Sprintf("expires_at = $%d", argID))
+		args = append(args, *upd.ExpiresAt)
+		argID += 1
+	}
+
+	if len(setValues) == 0 {
+		return repository.NewErrEmptyUpdate(op, "lot")
+	}
+
+	args = append(args, id)
+	query := fmt.Sprintf("UPDATE lots SET %s WHERE id = $%d RETURNING medicine_id", strings.Join(setValues, ", "), argID)
+
+	var medicineID int64
+	err := l.db.QueryRowContext(ctx, query, args...).Scan(&medicineID)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return repository.NewNotFoundError(op, "lot", id)
+	case isUniqueViolation(err, lotsNumberKey):
+		_ = l.db.QueryRowContext(ctx, "SELECT medicine_id FROM lots WHERE id = $1", id).Scan(&medicineID)
+		return repository.NewErrDuplicateLot(medicineID, *upd.LotNumber)
+	case err != nil:
+		return fmt.Errorf("%s: failed to update lot: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetStatus moves the listed lots to status and returns the ids that changed.
+func (l *Lots) SetStatus(ctx context.Context, ids []int64, status domain.LotStatus) ([]int64, error) {
+	const op = "repository.psql.lots.SetStatus"
+	const query = "UPDATE lots SET status = $1 WHERE id = ANY($2) AND status <> $1 RETURNING id"
+
+	rows, err := l.db.QueryContext(ctx, query, status, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to update lot status: %w", op, err)
+	}
+	defer rows.Close()
+
+	changed := make([]int64, 0, len(ids))
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan lot id: %w", op, err)
+		}
+		changed = append(changed, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return changed, nil
+}
+
+func (l *Lots) Delete(ctx context.Context, id int64) error {
+	const op = "repository.psql.lots.Delete"
+
+	result, err := l.db.ExecContext(ctx, "DELETE FROM lots WHERE id = $1", id)
+	if isForeignKeyViolation(err, stockMovementsLotFK) {
+		return repository.NewErrLotInUse(id)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete lot: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "lot", id)
+	}
+
+	return nil
+}
+
+func scanLot(row rowScanner) (domain.Lot, error) {
+	var lot domain.Lot
+	err := row.Scan(
+		&lot.ID,
+		&lot.MedicineID,
+		&lot.LotNumber,
+		&lot.ExpiresAt,
+		&lot.Status,
+		&lot.CreatedAt,
+		&lot.OnHand,
+	)
+	return lot, err
+}
@@ -0,0 +1,229 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+const (
+	manufacturersNameKey    = "manufacturers_name_key"
+	medicinesManufacturerFK = "medicines_manufacturer_id_fkey"
+
+	manufacturerColumns = "id, name, created_at, updated_at"
+)
+
+type Manufacturers struct {
+	db *sql.DB
+}
+
+func NewManufacturers(db *sql.DB) *Manufacturers {
+	return &Manufacturers{
+		db: db,
+	}
+}
+
+func (m *Manufacturers) Create(ctx context.Context, manufacturer domain.Manufacturer) (int64, error) {
+	const op = "repository.psql.manufacturers.Create"
+	const query = `
+		INSERT INTO manufacturers (name, created_at, updated_at)
+		VALUES ($1, $2, $2)
+		RETURNING id
+	`
+
+	var id int64
+	err := m.db.QueryRowContext(ctx, query, manufacturer.Name, manufacturer.CreatedAt).Scan(&id)
+	if isUniqueViolation(err, manufacturersNameKey) {
+		return 0, m.duplicate(ctx, op, manufacturer.Name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to create manufacturer: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// duplicate builds ErrDuplicateManufacturer pointing at the row that owns name.
+func (m *Manufacturers) duplicate(ctx context.Context, op, name string) error {
+	existing, err := m.FindByName(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get manufacturer with duplicated name: %w", op, err)
+	}
+
+	return repository.NewErrDuplicateManufacturer(name, existing.ID)
+}
+
+func (m *Manufacturers) GetByID(ctx context.Context, id int64) (domain.Manufacturer, error) {
+	const op = "repository.psql.manufacturers.GetByID"
+
+	manufacturer, err := scanManufacturer(m.db.QueryRowContext(ctx,
+		"SELECT "+manufacturerColumns+" FROM manufacturers WHERE id = $1", id))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.Manufacturer{}, repository.NewNotFoundError(op, "manufacturer", id)
+	case err != nil:
+		return domain.Manufacturer{}, fmt.Errorf("%s: failed to get manufacturer by id: %w", op, err)
+	}
+
+	return manufacturer, nil
+}
+
+// FindByName looks a manufacturer up by its normalized name.
+func (m *Manufacturers) FindByName(ctx context.Context, name string) (domain.Manufacturer, error) {
+	const op = "repository.psql.manufacturers.FindByName"
+
+	manufacturer, err := scanManufacturer(m.db.QueryRowContext(ctx,
+		"SELECT "+manufacturerColumns+" FROM manufacturers WHERE lower(name) = $1",
+		domain.NormalizeManufacturerName(name)))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.Manufacturer{}, repository.NewNotFoundError(op, "manufacturer", name)
+	case err != nil:
+		return domain.Manufacturer{}, fmt.Errorf("%s: failed to get manufacturer by name: %w", op, err)
+	}
+
+	return manufacturer, nil
+}
+
+// Resolve returns the manufacturer named name, creating it if needed.
+func (m *Manufacturers) Resolve(ctx context.Context, name string) (domain.Manufacturer, error) {
+	const op = "repository.psql.manufacturers.Resolve"
+	const query = `
+		INSERT INTO manufacturers (name, created_at, updated_at)
+		VALUES ($1, $2, $2)
+		ON CONFLICT (lower(name)) DO NOTHING
+	`
+
+	if _, err := m.db.ExecContext(ctx, query, name, time.Now()); err != nil {
+		return domain.Manufacturer{}, fmt.Errorf("%s: failed to create manufacturer: %w", op, err)
+	}
+
+	return m.FindByName(ctx, name)
+}
+
+func (m *Manufacturers) List(ctx context.Context) ([]domain.Manufacturer, error) {
+	const op = "repository.psql.manufacturers.List"
+
+	rows, err := m.db.QueryContext(ctx, "SELECT "+manufacturerColumns+" FROM manufacturers ORDER BY name, id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get manufacturers: %w", op, err)
+	}
+	defer rows.Close()
+
+	manufacturers := make([]domain.Manufacturer, 0)
+	for rows.Next() {
+		manufacturer, err := scanManufacturer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to scan manufacturer row: %w", op, err)
+		}
+		manufacturers = append(manufacturers, manufacturer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return manufacturers, nil
+}
+
+// Update renames a manufacturer and rewrites the pharma_company copy on its
+// medicines in the same transaction, recording rev for each of them.
+func (m *Manufacturers) Update(ctx context.Context, id int64, upd domain.UpdateManufacturer, rev domain.MedicineRevision) error {
+	const op = "repository.psql.manufacturers.Update"
+
+	if upd.Name == nil {
+		return repository.NewErrEmptyUpdate(op, "manufacturer")
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE manufacturers SET name = $1, updated_at = $2 WHERE id = $3",
+		*upd.Name, time.Now(), id,
+	)
+	if isUniqueViolation(err, manufacturersNameKey) {
+		return m.duplicate(ctx, op, *upd.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to update manufacturer: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "manufacturer", id)
+	}
+
+	renamed, err := lockMedicines(ctx, tx, "manufacturer_id = $1 AND pharma_company <> $2", id, *upd.Name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE medicines SET pharma_company = $1, version = version + 1
+		WHERE manufacturer_id = $2 AND pharma_company <> $1`,
+		*upd.Name, id,
+	); err != nil {
+		return fmt.Errorf("%s: failed to rename manufacturer on medicines: %w", op, err)
+	}
+
+	if err = reviseMedicines(ctx, tx, renamed, rev); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return nil
+}
+
+func (m *Manufacturers) Delete(ctx context.Context, id int64) error {
+	const op = "repository.psql.manufacturers.Delete"
+
+	result, err := m.db.ExecContext(ctx, "DELETE FROM manufacturers WHERE id = $1", id)
+	if isForeignKeyViolation(err, medicinesManufacturerFK) {
+		return repository.NewErrManufacturerInUse(id)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete manufacturer: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "manufacturer", id)
+	}
+
+	return nil
+}
+
+func scanManufacturer(row rowScanner) (domain.Manufacturer, error) {
+	var manufacturer domain.Manufacturer
+	err := row.Scan(
+		&manufacturer.ID,
+		&manufacturer.Name,
+		&manufacturer.CreatedAt,
+		&manufacturer.UpdatedAt,
+	)
+	return manufacturer, err
+}
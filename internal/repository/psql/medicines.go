@@ -6,11 +6,63 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 
 	"hippo/internal/domain"
 	"hippo/internal/repository"
 )
 
+const (
+	medicinesNDCKey = "medicines_ndc_key"
+
+	medicineColumns = "id, ndc, name, dosage, form, active_ingredient, pharma_company, manufacturer_id, deleted_at, version, " +
+		medicineIngredientsColumn + ", " + medicineCategoriesColumn
+)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMedicine(row rowScanner, medicine *domain.Medicine, extra ...any) error {
+	var (
+		manufacturerID sql.NullInt64
+		ingredients    []byte
+	)
+	err := row.Scan(append([]any{
+		&medicine.ID,
+		&medicine.NDC,
+		&medicine.Name,
+		&medicine.Dosage,
+		&medicine.Form,
+		&medicine.ActiveIngredient,
+		&medicine.PharmaCompany,
+		&manufacturerID,
+		&medicine.DeletedAt,
+		&medicine.Version,
+		&ingredients,
+		pq.Array(&medicine.Categories),
+	}, extra...)...)
+	if err != nil {
+		return err
+	}
+
+	if medicine.Ingredients, err = decodeMedicineIngredients(ingredients); err != nil {
+		return fmt.Errorf("failed to decode ingredients: %w", err)
+	}
+	if medicine.Categories == nil {
+		medicine.Categories = make([]string, 0)
+	}
+
+	medicine.Strength = domain.ParseStrength(medicine.Dosage)
+	medicine.ManufacturerID = nil
+	if manufacturerID.Valid {
+		medicine.ManufacturerID = &manufacturerID.Int64
+	}
+	return nil
+}
+
 type Medicines struct {
 	db *sql.DB
 }
@@ -21,89 +73,294 @@ func NewMedicines(db *sql.DB) *Medicines {
 	}
 }
 
-func (m *Medicines) Create(ctx context.Context, medicine domain.Medicine) (int64, error) {
+// Create inserts a medicine and records rev as its first revision. The
+// medicine id is filled into rev by Create.
+func (m *Medicines) Create(ctx context.Context, medicine domain.Medicine, rev domain.MedicineRevision) (int64, error) {
 	const op = "repository.psql.medicines.Create"
 	const query = `
 		INSERT INTO medicines 
-			(ndc, name, dosage, form, active_ingredient, pharma_company) 
-		VALUES ($1, $2, $3, $4, $5, $6) 
+			(ndc, name, dosage, form, active_ingredient, pharma_company, manufacturer_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7) 
+		ON CONFLICT (ndc) WHERE deleted_at IS NULL DO NOTHING
 		RETURNING id
 	`
 
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
 	var id int64
-	err := m.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		medicine.NDC,
 		medicine.Name,
 		medicine.Dosage,
 		medicine.Form,
 		medicine.ActiveIngredient,
 		medicine.PharmaCompany,
+		sqlNullInt64(medicine.ManufacturerID),
 	).Scan(&id)
 
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, m.duplicateNDC(ctx, op, medicine.NDC)
+	}
+
 	if err != nil {
 		return 0, fmt.Errorf("%s: failed to create medicine: %w", op, err)
 	}
 
+	if err = replaceMedicineIngredients(ctx, tx, id, medicine.Ingredients); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = replaceMedicineCategories(ctx, tx, id, medicine.Categories); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rev.MedicineID = id
+	rev.Snapshot.ID = int(id)
+	if err = insertRevision(ctx, tx, rev); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
 	return id, nil
 }
 
-func (m *Medicines) GetAll(ctx context.Context) ([]domain.Medicine, error) {
+// duplicateNDC builds ErrDuplicateNDC pointing at the row that owns ndc.
+func (m *Medicines) duplicateNDC(ctx context.Context, op, ndc string) error {
+	var existingID int64
+	err := m.db.QueryRowContext(ctx, "SELECT id FROM medicines WHERE ndc = $1 AND deleted_at IS NULL", ndc).Scan(&existingID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get medicine with duplicated ndc: %w", op, err)
+	}
+
+	return repository.NewErrDuplicateNDC(ndc, existingID)
+}
+
+func (m *Medicines) GetAll(ctx context.Context, opts domain.MedicineListOptions) (domain.MedicinePage, error) {
 	const op = "repository.psql.medicines.GetAll"
-	const query = `
-		SELECT 
-			id, ndc, name, dosage, form, active_ingredient, pharma_company 
+
+	conds, args := medicineFilterConds(opts.Filter)
+	if !opts.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+
+	total, err := m.count(ctx, conds, args)
+	if err != nil {
+		return domain.MedicinePage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	orderBy, cmp := medicineOrder(opts)
+
+	if opts.After != nil {
+		if opts.SortBy == domain.MedicineSortByName {
+			conds = append(conds, fmt.Sprintf("(name, id) %s ($%d, $%d)", cmp, len(args)+1, len(args)+2))
+			args = append(args, opts.After.Name, opts.After.ID)
+		} else {
+			conds = append(conds, fmt.Sprintf("id %s $%d", cmp, len(args)+1))
+			args = append(args, opts.After.ID)
+		}
+	}
+
+	// one extra row tells us whether there is a next page
+	args = append(args, opts.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM medicines
-	`
+		%s
+		ORDER BY %s
+		LIMIT $%d
+	`, medicineColumns, whereClause(conds), orderBy, len(args))
 
-	rows, err := m.db.QueryContext(ctx, query)
+	rows, err := m.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("%s: failed to get medicines: %w", op, err)
+		return domain.MedicinePage{}, fmt.Errorf("%s: failed to get medicines: %w", op, err)
 	}
 	defer rows.Close()
 
-	var medicines []domain.Medicine
+	medicines := make([]domain.Medicine, 0, opts.Limit+1)
 	for rows.Next() {
 		var medicine domain.Medicine
-		if err = rows.Scan(
-			&medicine.ID,
-			&medicine.NDC,
-			&medicine.Name,
-			&medicine.Dosage,
-			&medicine.Form,
-			&medicine.ActiveIngredient,
-			&medicine.PharmaCompany,
-		); err != nil {
-			return nil, fmt.Errorf("%s: failed to scan medicine row: %w", op, err)
+		if err = scanMedicine(rows, &medicine); err != nil {
+			return domain.MedicinePage{}, fmt.Errorf("%s: failed to scan medicine row: %w", op, err)
 		}
 		medicines = append(medicines, medicine)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+		return domain.MedicinePage{}, fmt.Errorf("%s: error during rows iteration: %w", op, err)
 	}
 
-	return medicines, nil
+	page := domain.MedicinePage{
+		Items: medicines,
+		Total: total,
+	}
+
+	if len(medicines) > opts.Limit {
+		page.Items = medicines[:opts.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = domain.MedicineCursor{ID: int64(last.ID), Name: last.Name}.Encode()
+	}
+
+	return page, nil
 }
 
-func (m *Medicines) GetByID(ctx context.Context, id int64) (domain.Medicine, error) {
+// Export streams every medicine matching opts to fn in sort order, without
+// paging. Iteration stops at the first error returned by fn.
+func (m *Medicines) Export(ctx context.Context, opts domain.MedicineListOptions, fn func(domain.Medicine) error) error {
+	const op = "repository.psql.medicines.Export"
+
+	conds, args := medicineFilterConds(opts.Filter)
+	if !opts.IncludeDeleted {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+
+	orderBy, _ := medicineOrder(opts)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM medicines
+		%s
+		ORDER BY %s
+	`, medicineColumns, whereClause(conds), orderBy)
+
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: failed to get medicines: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var medicine domain.Medicine
+		if err = scanMedicine(rows, &medicine); err != nil {
+			return fmt.Errorf("%s: failed to scan medicine row: %w", op, err)
+		}
+
+		if err = fn(medicine); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return nil
+}
+
+// medicineOrder returns the ORDER BY clause for opts and the keyset
+// comparison operator that moves forward in that order.
+func medicineOrder(opts domain.MedicineListOptions) (orderBy, cmp string) {
+	cmp = ">"
+	dir := "ASC"
+	if opts.Order == domain.SortDesc {
+		cmp = "<"
+		dir = "DESC"
+	}
+
+	orderBy = fmt.Sprintf("id %s", dir)
+	if opts.SortBy == domain.MedicineSortByName {
+		orderBy = fmt.Sprintf("name %s, id %s", dir, dir)
+	}
+
+	return orderBy, cmp
+}
+
+func (m *Medicines) count(ctx context.Context, conds []string, args []interface{}) (int64, error) {
+	query := fmt.Sprintf("SELECT COUNT(*) FROM medicines %s", whereClause(conds))
+
+	var total int64
+	if err := m.db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to count medicines: %w", err)
+	}
+
+	return total, nil
+}
+
+func medicineFilterConds(f domain.MedicineFilter) ([]string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if f.NDC != "" {
+		args = append(args, f.NDC)
+		conds = append(conds, fmt.Sprintf("ndc = $%d", len(args)))
+	}
+
+	if f.Form != "" {
+		args = append(args, f.Form)
+		conds = append(conds, fmt.Sprintf("lower(form) = lower($%d)", len(args)))
+	}
+
+	if f.PharmaCompany != "" {
+		args = append(args, f.PharmaCompany)
+		conds = append(conds, fmt.Sprintf("lower(pharma_company) = lower($%d)", len(args)))
+	}
+
+	if f.ActiveIngredient != "" {
+		args = append(args, f.ActiveIngredient)
+		conds = append(conds, fmt.Sprintf("lower(active_ingredient) = lower($%d)", len(args)))
+	}
+
+	if f.Ingredient != "" {
+		args = append(args, f.Ingredient)
+		conds = append(conds, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM medicine_ingredients mi
+			JOIN ingredients i ON i.id = mi.ingredient_id
+			WHERE mi.medicine_id = medicines.id AND lower(i.name) = lower($%d)
+		)`, len(args)))
+	}
+
+	if f.ManufacturerID > 0 {
+		args = append(args, f.ManufacturerID)
+		conds = append(conds, fmt.Sprintf("manufacturer_id = $%d", len(args)))
+	}
+
+	if f.CategoryID > 0 {
+		args = append(args, f.CategoryID)
+		categories := fmt.Sprintf("$%d", len(args))
+		if f.CategoryRecursive {
+			categories = fmt.Sprintf(`
+				WITH RECURSIVE subtree AS (
+					SELECT id FROM categories WHERE id = $%d
+					UNION
+					SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+				)
+				SELECT id FROM subtree`, len(args))
+		}
+		conds = append(conds, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM medicine_categories mc
+			WHERE mc.medicine_id = medicines.id AND mc.category_id IN (%s)
+		)`, categories))
+	}
+
+	return conds, args
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conds, " AND ")
+}
+
+func (m *Medicines) GetByID(ctx context.Context, id int64, includeDeleted bool) (domain.Medicine, error) {
 	const op = "repository.psql.medicines.GetByID"
-	const query = `
-		SELECT 
-			id, ndc, name, dosage, form, active_ingredient, pharma_company 
-		FROM medicines 
-		WHERE id = $1
-	`
+
+	query := "SELECT " + medicineColumns + " FROM medicines WHERE id = $1"
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
 
 	var medicine domain.Medicine
-	err := m.db.QueryRowContext(ctx, query, id).Scan(
-		&medicine.ID,
-		&medicine.NDC,
-		&medicine.Name,
-		&medicine.Dosage,
-		&medicine.Form,
-		&medicine.ActiveIngredient,
-		&medicine.PharmaCompany,
-	)
+	err := scanMedicine(m.db.QueryRowContext(ctx, query, id), &medicine)
 
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
@@ -115,7 +372,32 @@ func (m *Medicines) GetByID(ctx context.Context, id int64) (domain.Medicine, err
 	return medicine, nil
 }
 
-func (m *Medicines) Update(ctx context.Context, id int64, upd domain.UpdateMedicine) error {
+func (m *Medicines) GetByNDC(ctx context.Context, ndc string, includeDeleted bool) (domain.Medicine, error) {
+	const op = "repository.psql.medicines.GetByNDC"
+
+	query := "SELECT " + medicineColumns + " FROM medicines WHERE ndc = $1"
+	if !includeDeleted {
+		query += " AND deleted_at IS NULL"
+	}
+	// several tombstones may share an ndc, prefer the live row, then the latest deleted
+	query += " ORDER BY deleted_at DESC NULLS FIRST LIMIT 1"
+
+	var medicine domain.Medicine
+	err := scanMedicine(m.db.QueryRowContext(ctx, query, ndc), &medicine)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.Medicine{}, repository.NewNotFoundError(op, "medicine", ndc)
+	case err != nil:
+		return domain.Medicine{}, fmt.Errorf("%s: failed to get medicine by ndc: %w", op, err)
+	}
+
+	return medicine, nil
+}
+
+// Update applies upd to a live medicine, conditionally on ifVersion when
+// non-zero, and records rev in the same transaction unless it is nil.
+func (m *Medicines) Update(ctx context.Context, id int64, upd domain.UpdateMedicine, ifVersion int, rev *domain.MedicineRevision) error {
 	const op = "repository.psql.medicines.Update"
 	var (
 		setValues []string
@@ -159,18 +441,42 @@ func (m *Medicines) Update(ctx context.Context, id int64, upd domain.UpdateMedic
 		argID += 1
 	}
 
-	if len(setValues) == 0 {
+	if upd.ManufacturerID != nil {
+		setValues = append(setValues, fmt.Sprintf("manufacturer_id = $%d", argID))
+		args = append(args, sql.NullInt64{Int64: *upd.ManufacturerID, Valid: *upd.ManufacturerID > 0})
+		argID += 1
+	}
+
+	if len(setValues) == 0 && upd.Ingredients == nil && upd.Categories == nil {
 		return repository.NewErrEmptyUpdate(op, "medicine")
 	}
 
+	setValues = append(setValues, "version = version + 1")
+
 	args = append(args, id)
 	query := fmt.Sprintf(
-		"UPDATE medicines SET %s WHERE id = $%d",
+		"UPDATE medicines SET %s WHERE id = $%d AND deleted_at IS NULL",
 		strings.Join(setValues, ", "),
 		argID,
 	)
 
-	result, err := m.db.ExecContext(ctx, query, args...)
+	if ifVersion > 0 {
+		args = append(args, ifVersion)
+		query += fmt.Sprintf(" AND version = $%d", argID+1)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if isUniqueViolation(err, medicinesNDCKey) {
+		return m.duplicateNDC(ctx, op, *upd.NDC)
+	}
 	if err != nil {
 		return fmt.Errorf("%s: failed to update medicine: %w", op, err)
 	}
@@ -181,15 +487,55 @@ func (m *Medicines) Update(ctx context.Context, id int64, upd domain.UpdateMedic
 	}
 
 	if rowsAffected == 0 {
-		return repository.NewNotFoundError(op, "medicine", 0)
+		return m.missedUpdate(ctx, op, id, ifVersion)
+	}
+
+	if upd.Ingredients != nil {
+		if err = replaceMedicineIngredients(ctx, tx, id, *upd.Ingredients); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if upd.Categories != nil {
+		if err = replaceMedicineCategories(ctx, tx, id, *upd.Categories); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if rev != nil {
+		rev.MedicineID = id
+		if err = insertRevision(ctx, tx, *rev); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit failed: %w", op, err)
 	}
 	return nil
 }
 
-func (m *Medicines) Delete(ctx context.Context, id int64) error {
+// Delete tombstones a live medicine, conditionally on ifVersion when non-zero,
+// and records rev in the same transaction.
+func (m *Medicines) Delete(ctx context.Context, id int64, ifVersion int, rev domain.MedicineRevision) error {
 	const op = "repository.psql.medicines.Delete"
 
-	result, err := m.db.ExecContext(ctx, "DELETE FROM medicines WHERE id = $1", id)
+	query := "UPDATE medicines SET deleted_at = now(), version = version + 1 WHERE id = $1 AND deleted_at IS NULL"
+	args := []interface{}{id}
+	if ifVersion > 0 {
+		query += " AND version = $2"
+		args = append(args, ifVersion)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("%s: failed to delete medicine: %w", op, err)
 	}
@@ -200,8 +546,259 @@ func (m *Medicines) Delete(ctx context.Context, id int64) error {
 	}
 
 	if rowsAffected == 0 {
+		return m.missedUpdate(ctx, op, id, ifVersion)
+	}
+
+	rev.MedicineID = id
+	if err = insertRevision(ctx, tx, rev); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return nil
+}
+
+// missedUpdate explains why a conditional write touched no rows: the live
+// medicine is either gone or has moved past ifVersion.
+func (m *Medicines) missedUpdate(ctx context.Context, op string, id int64, ifVersion int) error {
+	if ifVersion == 0 {
 		return repository.NewNotFoundError(op, "medicine", id)
 	}
 
+	var current int
+	err := m.db.QueryRowContext(ctx,
+		"SELECT version FROM medicines WHERE id = $1 AND deleted_at IS NULL", id,
+	).Scan(&current)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return repository.NewNotFoundError(op, "medicine", id)
+	case err != nil:
+		return fmt.Errorf("%s: failed to get medicine version: %w", op, err)
+	}
+
+	return repository.NewErrVersionMismatch(op, "medicine", id, ifVersion, current)
+}
+
+// Restore brings a tombstoned medicine back and records rev in the same
+// transaction. The snapshot of rev is replaced by the restored row.
+func (m *Medicines) Restore(ctx context.Context, id int64, rev domain.MedicineRevision) error {
+	const op = "repository.psql.medicines.Restore"
+	const query = "UPDATE medicines SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL"
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	result, err := tx.ExecContext(ctx, query, id)
+	if isUniqueViolation(err, medicinesNDCKey) {
+		var ndc string
+		if err = m.db.QueryRowContext(ctx, "SELECT ndc FROM medicines WHERE id = $1", id).Scan(&ndc); err != nil {
+			return fmt.Errorf("%s: failed to get ndc of restored medicine: %w", op, err)
+		}
+		return m.duplicateNDC(ctx, op, ndc)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to restore medicine: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return repository.NewNotFoundError(op, "deleted medicine", id)
+	}
+
+	restoredQuery := "SELECT " + medicineColumns + " FROM medicines WHERE id = $1"
+	if err = scanMedicine(tx.QueryRowContext(ctx, restoredQuery, id), &rev.Snapshot); err != nil {
+		return fmt.Errorf("%s: failed to get restored medicine: %w", op, err)
+	}
+
+	rev.MedicineID = id
+	if err = insertRevision(ctx, tx, rev); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
 	return nil
 }
+
+// Purge physically removes medicines deleted before the given time.
+func (m *Medicines) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	const op = "repository.psql.medicines.Purge"
+	// medicines with stock history stay, the ledger must remain complete
+	const query = `
+		DELETE FROM medicines
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			AND NOT EXISTS (SELECT 1 FROM stock_movements WHERE medicine_id = medicines.id)
+	`
+
+	result, err := m.db.ExecContext(ctx, query, deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to purge medicines: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to get rows affected: %w", op, err)
+	}
+
+	return rowsAffected, nil
+}
+
+func (m *Medicines) Search(ctx context.Context, q string, limit int) ([]domain.MedicineSearchResult, error) {
+	const op = "repository.psql.medicines.Search"
+	query := `
+		SELECT ` + medicineColumns + `, score
+		FROM (
+			SELECT *,
+				GREATEST(
+					word_similarity($1, coalesce(name, '')),
+					word_similarity($1, coalesce(active_ingredient, '')),
+					word_similarity($1, coalesce(pharma_company, ''))
+				) AS score
+			FROM medicines
+			WHERE deleted_at IS NULL AND (
+				search_vector @@ plainto_tsquery('simple', $1)
+				OR $1 <% name
+				OR $1 <% active_ingredient
+				OR $1 <% pharma_company
+			)
+		) medicines
+		ORDER BY score DESC, id
+		LIMIT $2
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to search medicines: %w", op, err)
+	}
+	defer rows.Close()
+
+	results := make([]domain.MedicineSearchResult, 0)
+	for rows.Next() {
+		var res domain.MedicineSearchResult
+		if err = scanMedicine(rows, &res.Medicine, &res.Score); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan medicine row: %w", op, err)
+		}
+		results = append(results, res)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// GetByNDCs returns the live medicines owning any of ndcs, keyed by ndc.
+func (m *Medicines) GetByNDCs(ctx context.Context, ndcs []string) (map[string]domain.Medicine, error) {
+	const op = "repository.psql.medicines.GetByNDCs"
+
+	query := "SELECT " + medicineColumns + " FROM medicines WHERE ndc = ANY($1) AND deleted_at IS NULL"
+
+	rows, err := m.db.QueryContext(ctx, query, pq.Array(ndcs))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get medicines: %w", op, err)
+	}
+	defer rows.Close()
+
+	medicines := make(map[string]domain.Medicine, len(ndcs))
+	for rows.Next() {
+		var medicine domain.Medicine
+		if err = scanMedicine(rows, &medicine); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan medicine row: %w", op, err)
+		}
+		medicines[medicine.NDC] = medicine
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return medicines, nil
+}
+
+// UpsertBatch inserts or updates medicines by NDC in one transaction,
+// recording revs[i] for medicines[i], and returns their ids in input order.
+func (m *Medicines) UpsertBatch(ctx context.Context, medicines []domain.Medicine, revs []domain.MedicineRevision) ([]int64, error) {
+	const op = "repository.psql.medicines.UpsertBatch"
+	const query = `
+		INSERT INTO medicines 
+			(ndc, name, dosage, form, active_ingredient, pharma_company, manufacturer_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7) 
+		ON CONFLICT (ndc) WHERE deleted_at IS NULL DO UPDATE SET
+			name = EXCLUDED.name,
+			dosage = EXCLUDED.dosage,
+			form = EXCLUDED.form,
+			active_ingredient = EXCLUDED.active_ingredient,
+			pharma_company = EXCLUDED.pharma_company,
+			manufacturer_id = EXCLUDED.manufacturer_id,
+			version = medicines.version + 1
+		RETURNING id
+	`
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: prepare failed: %w", op, err)
+	}
+	defer stmt.Close()
+
+	ids := make([]int64, len(medicines))
+	for i, medicine := range medicines {
+		err = stmt.QueryRowContext(ctx,
+			medicine.NDC,
+			medicine.Name,
+			medicine.Dosage,
+			medicine.Form,
+			medicine.ActiveIngredient,
+			medicine.PharmaCompany,
+			sqlNullInt64(medicine.ManufacturerID),
+		).Scan(&ids[i])
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to upsert medicine %s: %w", op, medicine.NDC, err)
+		}
+
+		if err = replaceMedicineIngredients(ctx, tx, ids[i], medicine.Ingredients); err != nil {
+			return nil, fmt.Errorf("%s: medicine %s: %w", op, medicine.NDC, err)
+		}
+
+		if err = replaceMedicineCategories(ctx, tx, ids[i], medicine.Categories); err != nil {
+			return nil, fmt.Errorf("%s: medicine %s: %w", op, medicine.NDC, err)
+		}
+
+		rev := revs[i]
+		rev.MedicineID = ids[i]
+		rev.Snapshot.ID = int(ids[i])
+		if err = insertRevision(ctx, tx, rev); err != nil {
+			return nil, fmt.Errorf("%s: medicine %s: %w", op, medicine.NDC, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return ids, nil
+}
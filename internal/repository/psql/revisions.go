@@ -0,0 +1,229 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+type MedicineRevisions struct {
+	db *sql.DB
+}
+
+func NewMedicineRevisions(db *sql.DB) *MedicineRevisions {
+	return &MedicineRevisions{
+		db: db,
+	}
+}
+
+func (r *MedicineRevisions) List(ctx context.Context, medicineID int64) ([]domain.MedicineRevision, error) {
+	const op = "repository.psql.revisions.List"
+	const query = `
+		SELECT id, medicine_id, revision, action, changes, snapshot, user_id, created_at
+		FROM medicine_revisions
+		WHERE medicine_id = $1
+		ORDER BY revision
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, medicineID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get revisions: %w", op, err)
+	}
+	defer rows.Close()
+
+	revisions := make([]domain.MedicineRevision, 0)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return revisions, nil
+}
+
+func (r *MedicineRevisions) Get(ctx context.Context, medicineID int64, revision int) (domain.MedicineRevision, error) {
+	const op = "repository.psql.revisions.Get"
+	const query = `
+		SELECT id, medicine_id, revision, action, changes, snapshot, user_id, created_at
+		FROM medicine_revisions
+		WHERE medicine_id = $1 AND revision = $2
+	`
+
+	rev, err := scanRevision(r.db.QueryRowContext(ctx, query, medicineID, revision))
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.MedicineRevision{}, repository.NewNotFoundError(op, "revision", revision)
+	case err != nil:
+		return domain.MedicineRevision{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return rev, nil
+}
+
+func scanRevision(row rowScanner) (domain.MedicineRevision, error) {
+	var (
+		rev               domain.MedicineRevision
+		changes, snapshot []byte
+		userID            sql.NullInt64
+	)
+
+	err := row.Scan(
+		&rev.ID,
+		&rev.MedicineID,
+		&rev.Revision,
+		&rev.Action,
+		&changes,
+		&snapshot,
+		&userID,
+		&rev.CreatedAt,
+	)
+	if err != nil {
+		return domain.MedicineRevision{}, err
+	}
+
+	if err = json.Unmarshal(changes, &rev.Changes); err != nil {
+		return domain.MedicineRevision{}, fmt.Errorf("failed to decode changes: %w", err)
+	}
+	if err = json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return domain.MedicineRevision{}, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	rev.UserID = userID.Int64
+
+	return rev, nil
+}
+
+// insertRevision stores rev as the next revision of its medicine inside tx.
+// The caller must have written the medicine row in tx first: the row lock
+// serializes writers of one medicine, so the numbering cannot collide.
+func insertRevision(ctx context.Context, tx *sql.Tx, rev domain.MedicineRevision) error {
+	const query = `
+		INSERT INTO medicine_revisions
+			(medicine_id, revision, action, changes, snapshot, user_id, created_at)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6
+		FROM medicine_revisions
+		WHERE medicine_id = $1
+	`
+
+	if rev.Changes == nil {
+		rev.Changes = []domain.FieldChange{}
+	}
+
+	changes, err := json.Marshal(rev.Changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision changes: %w", err)
+	}
+
+	snapshot, err := json.Marshal(rev.Snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode revision snapshot: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, query,
+		rev.MedicineID,
+		rev.Action,
+		changes,
+		snapshot,
+		sql.NullInt64{Int64: rev.UserID, Valid: rev.UserID > 0},
+		rev.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return nil
+}
+
+// lockMedicines locks the medicines matching cond inside tx and returns them
+// as they are before a change that reaches them through another table.
+func lockMedicines(ctx context.Context, tx *sql.Tx, cond string, args ...any) ([]domain.Medicine, error) {
+	query := "SELECT " + medicineColumns + " FROM medicines WHERE " + cond + " ORDER BY id FOR UPDATE"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock medicines: %w", err)
+	}
+	defer rows.Close()
+
+	medicines := make([]domain.Medicine, 0)
+	for rows.Next() {
+		var medicine domain.Medicine
+		if err = scanMedicine(rows, &medicine); err != nil {
+			return nil, fmt.Errorf("failed to scan medicine row: %w", err)
+		}
+		medicines = append(medicines, medicine)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return medicines, nil
+}
+
+// reviseMedicines records rev for each of the medicines locked as before
+// that tx has changed since, with the snapshot and changes of every medicine
+// read back from tx.
+func reviseMedicines(ctx context.Context, tx *sql.Tx, before []domain.Medicine, rev domain.MedicineRevision) error {
+	if len(before) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(before))
+	previous := make(map[int]domain.Medicine, len(before))
+	for i, medicine := range before {
+		ids[i] = int64(medicine.ID)
+		previous[medicine.ID] = medicine
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT "+medicineColumns+" FROM medicines WHERE id = ANY($1) ORDER BY id", pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to get changed medicines: %w", err)
+	}
+
+	after := make([]domain.Medicine, 0, len(before))
+	for rows.Next() {
+		var medicine domain.Medicine
+		if err = scanMedicine(rows, &medicine); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan medicine row: %w", err)
+		}
+		after = append(after, medicine)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	for _, medicine := range after {
+		changes := previous[medicine.ID].Diff(medicine)
+		if len(changes) == 0 {
+			continue
+		}
+
+		rev.MedicineID = int64(medicine.ID)
+		rev.Changes = changes
+		rev.Snapshot = medicine
+		if err = insertRevision(ctx, tx, rev); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
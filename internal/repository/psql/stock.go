@@ -0,0 +1,261 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+type Stock struct {
+	db *sql.DB
+}
+
+func NewStock(db *sql.DB) *Stock {
+	return &Stock{
+		db: db,
+	}
+}
+
+// Record appends movements of one medicine to the ledger in a single
+// transaction. The medicine row is locked so concurrent movements are
+// serialized, and no location may end up with negative stock. Every outgoing
+// movement is also checked against the balance of its own lot, or of the
+// stock held without a lot, so lot stock cannot leave as unlotted stock.
+func (s *Stock) Record(ctx context.Context, medicineID int64, movements []domain.StockMovement) ([]domain.StockMovement, error) {
+	const op = "repository.psql.stock.Record"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: begin tx: %w", op, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var locked int64
+	err = tx.QueryRowContext(ctx,
+		"SELECT id FROM medicines WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", medicineID,
+	).Scan(&locked)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, repository.NewNotFoundError(op, "medicine", medicineID)
+	case err != nil:
+		return nil, fmt.Errorf("%s: failed to lock medicine: %w", op, err)
+	}
+
+	const insert = `
+		INSERT INTO stock_movements
+			(medicine_id, location, lot_id, type, quantity, reason, reference, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+
+	recorded := make([]domain.StockMovement, 0, len(movements))
+	for _, mv := range movements {
+		if mv.Quantity < 0 {
+			onHand, err := onHandAt(ctx, tx, medicineID, mv.Location)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			if onHand+mv.Quantity < 0 {
+				return nil, repository.NewErrInsufficientStock(medicineID, mv.Location, onHand, -mv.Quantity)
+			}
+			lotOnHand, err := lotOnHandAt(ctx, tx, medicineID, mv.LotID, mv.Location)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+			if lotOnHand+mv.Quantity < 0 {
+				return nil, repository.NewErrInsufficientStock(medicineID, mv.Location, lotOnHand, -mv.Quantity)
+			}
+		}
+
+		mv.MedicineID = medicineID
+		err = tx.QueryRowContext(ctx, insert,
+			mv.MedicineID,
+			mv.Location,
+			sqlNullInt64(mv.LotID),
+			mv.Type,
+			mv.Quantity,
+			mv.Reason,
+			mv.Reference,
+			sql.NullInt64{Int64: mv.UserID, Valid: mv.UserID > 0},
+			mv.CreatedAt,
+		).Scan(&mv.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to insert movement: %w", op, err)
+		}
+
+		recorded = append(recorded, mv)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: commit failed: %w", op, err)
+	}
+
+	return recorded, nil
+}
+
+func onHandAt(ctx context.Context, tx *sql.Tx, medicineID int64, location string) (int, error) {
+	const query = `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM stock_movements
+		WHERE medicine_id = $1 AND location = $2
+	`
+
+	var onHand int
+	if err := tx.QueryRowContext(ctx, query, medicineID, location).Scan(&onHand); err != nil {
+		return 0, fmt.Errorf("failed to compute on-hand stock: %w", err)
+	}
+
+	return onHand, nil
+}
+
+// lotOnHandAt sums one lot at a location. A nil lot sums the stock recorded
+// without a lot, so an unlotted movement cannot draw on expired lots.
+func lotOnHandAt(ctx context.Context, tx *sql.Tx, medicineID int64, lotID *int64, location string) (int, error) {
+	const query = `
+		SELECT COALESCE(SUM(quantity), 0)
+		FROM stock_movements
+		WHERE medicine_id = $1 AND lot_id IS NOT DISTINCT FROM $2 AND location = $3
+	`
+
+	var onHand int
+	if err := tx.QueryRowContext(ctx, query, medicineID, sqlNullInt64(lotID), location).Scan(&onHand); err != nil {
+		return 0, fmt.Errorf("failed to compute lot on-hand stock: %w", err)
+	}
+
+	return onHand, nil
+}
+
+func sqlNullInt64(v *int64) sql.NullInt64 {
+	if v == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *v, Valid: true}
+}
+
+// Levels returns the on-hand quantity of a medicine per location.
+func (s *Stock) Levels(ctx context.Context, medicineID int64) ([]domain.StockItem, error) {
+	const op = "repository.psql.stock.Levels"
+	const query = `
+		SELECT location, SUM(quantity)
+		FROM stock_movements
+		WHERE medicine_id = $1
+		GROUP BY location
+		ORDER BY location
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, medicineID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get stock levels: %w", op, err)
+	}
+	defer rows.Close()
+
+	items := make([]domain.StockItem, 0)
+	for rows.Next() {
+		item := domain.StockItem{MedicineID: medicineID}
+		if err = rows.Scan(&item.Location, &item.OnHand); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan stock row: %w", op, err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return items, nil
+}
+
+func (s *Stock) Movements(ctx context.Context, medicineID int64) ([]domain.StockMovement, error) {
+	const op = "repository.psql.stock.Movements"
+	const query = `
+		SELECT id, medicine_id, location, lot_id, type, quantity, reason, reference, user_id, created_at
+		FROM stock_movements
+		WHERE medicine_id = $1
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, medicineID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get stock movements: %w", op, err)
+	}
+	defer rows.Close()
+
+	movements := make([]domain.StockMovement, 0)
+	for rows.Next() {
+		var (
+			mv     domain.StockMovement
+			lotID  sql.NullInt64
+			userID sql.NullInt64
+		)
+		if err = rows.Scan(
+			&mv.ID,
+			&mv.MedicineID,
+			&mv.Location,
+			&lotID,
+			&mv.Type,
+			&mv.Quantity,
+			&mv.Reason,
+			&mv.Reference,
+			&userID,
+			&mv.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan movement row: %w", op, err)
+		}
+		if lotID.Valid {
+			mv.LotID = &lotID.Int64
+		}
+		mv.UserID = userID.Int64
+		movements = append(movements, mv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return movements, nil
+}
+
+// LotBalances returns positive lot quantities of a medicine at a location
+// for active lots not expired on day now, first-expiring first.
+func (s *Stock) LotBalances(ctx context.Context, medicineID int64, location string, now time.Time) ([]domain.LotBalance, error) {
+	const op = "repository.psql.stock.LotBalances"
+	const query = `
+		SELECT m.lot_id, l.expires_at, SUM(m.quantity) AS on_hand
+		FROM stock_movements m
+		JOIN lots l ON l.id = m.lot_id
+		WHERE m.medicine_id = $1 AND m.location = $2
+			AND l.status = $3 AND l.expires_at > $4
+		GROUP BY m.lot_id, l.expires_at
+		HAVING SUM(m.quantity) > 0
+		ORDER BY l.expires_at, m.lot_id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, medicineID, location, domain.LotActive, domain.NewDate(now))
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get lot balances: %w", op, err)
+	}
+	defer rows.Close()
+
+	balances := make([]domain.LotBalance, 0)
+	for rows.Next() {
+		var b domain.LotBalance
+		if err = rows.Scan(&b.LotID, &b.ExpiresAt, &b.OnHand); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan lot balance: %w", op, err)
+		}
+		balances = append(balances, b)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return balances, nil
+}
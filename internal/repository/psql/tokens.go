@@ -5,11 +5,14 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"hippo/internal/domain"
 	"hippo/internal/repository"
 )
 
+const sessionColumns = `id, user_id, token_hash, user_agent, ip, created_at, last_used_at, expires_at`
+
 type Token struct {
 	db *sql.DB
 }
@@ -24,11 +27,13 @@ func (t *Token) Create(ctx context.Context, token domain.RefreshSession) error {
 	const op = "psql.refresh_tokens.Create"
 
 	query := `
-		INSERT INTO refresh_tokens (user_id, token, expires_at)
-		VALUES ($1, $2, $3)
+		INSERT INTO refresh_tokens (user_id, token_hash, user_agent, ip, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	_, err := t.db.ExecContext(ctx, query, token.UserID, token.Token, token.ExpiresAt)
+	_, err := t.db.ExecContext(ctx, query,
+		token.UserID, token.TokenHash, token.UserAgent, token.IP, token.CreatedAt, token.LastUsedAt, token.ExpiresAt,
+	)
 	if err != nil {
 		return fmt.Errorf("%s: failed to insert token: %w", op, err)
 	}
@@ -36,40 +41,221 @@ func (t *Token) Create(ctx context.Context, token domain.RefreshSession) error {
 	return nil
 }
 
-func (t *Token) Get(ctx context.Context, token string) (domain.RefreshSession, error) {
+func (t *Token) Get(ctx context.Context, tokenHash string) (domain.RefreshSession, error) {
 	const op = "psql.refresh_tokens.Get"
 
+	query := `SELECT ` + sessionColumns + ` FROM refresh_tokens WHERE token_hash = $1`
+
+	session, err := scanSession(t.db.QueryRowContext(ctx, query, tokenHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.RefreshSession{}, repository.NewErrTokenNotFound()
+	}
+	if err != nil {
+		return domain.RefreshSession{}, fmt.Errorf("%s: select failed: %w", op, err)
+	}
+
+	return session, nil
+}
+
+// GetByRotated returns the session a rotated-out token belonged to and when
+// the token was rotated. A session and all tokens it ever held form one
+// token family.
+func (t *Token) GetByRotated(ctx context.Context, tokenHash string) (domain.RefreshSession, time.Time, error) {
+	const op = "psql.refresh_tokens.GetByRotated"
+
+	query := `
+		SELECT r.rotated_at, s.id, s.user_id, s.token_hash, s.user_agent, s.ip,
+			s.created_at, s.last_used_at, s.expires_at
+		FROM rotated_refresh_tokens r
+		JOIN refresh_tokens s ON s.id = r.family_id
+		WHERE r.token_hash = $1
+	`
+
+	var (
+		rotatedAt time.Time
+		session   domain.RefreshSession
+	)
+	err := t.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&rotatedAt,
+		&session.ID, &session.UserID, &session.TokenHash, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.RefreshSession{}, time.Time{}, repository.NewErrTokenNotFound()
+	}
+	if err != nil {
+		return domain.RefreshSession{}, time.Time{}, fmt.Errorf("%s: select failed: %w", op, err)
+	}
+
+	return session, rotatedAt, nil
+}
+
+// Rotate replaces the refresh token of a session and records its use. The
+// update only applies while the session still holds oldTokenHash, so a token
+// can be redeemed once even under concurrent refreshes. The old token is
+// kept in the session's family to recognise it if it is presented again.
+func (t *Token) Rotate(ctx context.Context, oldTokenHash string, session domain.RefreshSession) error {
+	const op = "psql.refresh_tokens.Rotate"
+
 	tx, err := t.db.BeginTx(ctx, nil)
 	if err != nil {
-		return domain.RefreshSession{}, fmt.Errorf("%s: begin tx: %w", op, err)
+		return fmt.Errorf("%s: begin tx: %w", op, err)
 	}
 	defer func() {
 		_ = tx.Rollback()
 	}()
 
-	var session domain.RefreshSession
-	query := `SELECT id, user_id, token, expires_at FROM refresh_tokens WHERE token = $1`
+	query := `
+		UPDATE refresh_tokens
+		SET token_hash = $1, user_agent = $2, ip = $3, last_used_at = $4, expires_at = $5
+		WHERE id = $6 AND token_hash = $7
+	`
 
-	err = tx.QueryRowContext(ctx, query, token).Scan(
-		&session.ID, &session.UserID, &session.Token, &session.ExpiresAt,
+	res, err := tx.ExecContext(ctx, query,
+		session.TokenHash, session.UserAgent, session.IP, session.LastUsedAt, session.ExpiresAt, session.ID, oldTokenHash,
 	)
-
-	if errors.Is(err, sql.ErrNoRows) {
-		return domain.RefreshSession{}, repository.NewErrTokenNotFound()
+	if err != nil {
+		return fmt.Errorf("%s: update failed: %w", op, err)
 	}
 
+	affected, err := res.RowsAffected()
 	if err != nil {
-		return domain.RefreshSession{}, fmt.Errorf("%s: select failed: %w", op, err)
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if affected == 0 {
+		return repository.NewErrTokenNotFound()
 	}
 
-	delQuery := `DELETE FROM refresh_tokens WHERE token = $1`
-	if _, err := tx.ExecContext(ctx, delQuery, token); err != nil {
-		return domain.RefreshSession{}, fmt.Errorf("%s: delete failed: %w", op, err)
+	rotatedQuery := `
+		INSERT INTO rotated_refresh_tokens (token_hash, family_id, rotated_at)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := tx.ExecContext(ctx, rotatedQuery, oldTokenHash, session.ID, session.LastUsedAt); err != nil {
+		return fmt.Errorf("%s: insert rotated token: %w", op, err)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return domain.RefreshSession{}, fmt.Errorf("%s: commit failed: %w", op, err)
+		return fmt.Errorf("%s: commit failed: %w", op, err)
 	}
 
-	return session, nil
+	return nil
+}
+
+// PruneRotated forgets tokens rotated out before the given time and reports
+// how many there were.
+func (t *Token) PruneRotated(ctx context.Context, before time.Time) (int64, error) {
+	const op = "psql.refresh_tokens.PruneRotated"
+
+	res, err := t.db.ExecContext(ctx, `DELETE FROM rotated_refresh_tokens WHERE rotated_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("%s: delete failed: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+
+	return affected, nil
+}
+
+// ListByUser returns the sessions of a user that have not expired yet, most
+// recently used first.
+func (t *Token) ListByUser(ctx context.Context, userID int64) ([]domain.RefreshSession, error) {
+	const op = "psql.refresh_tokens.ListByUser"
+
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM refresh_tokens
+		WHERE user_id = $1 AND expires_at > $2
+		ORDER BY last_used_at DESC, id DESC
+	`
+
+	rows, err := t.db.QueryContext(ctx, query, userID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%s: select failed: %w", op, err)
+	}
+	defer rows.Close()
+
+	sessions := make([]domain.RefreshSession, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan failed: %w", op, err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows iteration: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+func (t *Token) Delete(ctx context.Context, tokenHash string) error {
+	const op = "psql.refresh_tokens.Delete"
+
+	res, err := t.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE token_hash = $1`, tokenHash)
+	if err != nil {
+		return fmt.Errorf("%s: delete failed: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if affected == 0 {
+		return repository.NewErrTokenNotFound()
+	}
+
+	return nil
+}
+
+// DeleteByID removes one session of a user. Sessions of other users are
+// reported as not found.
+func (t *Token) DeleteByID(ctx context.Context, userID, id int64) error {
+	const op = "psql.refresh_tokens.DeleteByID"
+
+	res, err := t.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("%s: delete failed: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if affected == 0 {
+		return repository.NewNotFoundError(op, "session", id)
+	}
+
+	return nil
+}
+
+// DeleteByUser removes every refresh session of a user and reports how many
+// there were.
+func (t *Token) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	const op = "psql.refresh_tokens.DeleteByUser"
+
+	res, err := t.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("%s: delete failed: %w", op, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+
+	return affected, nil
+}
+
+func scanSession(row rowScanner) (domain.RefreshSession, error) {
+	var session domain.RefreshSession
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.TokenHash, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt,
+	)
+	return session, err
 }
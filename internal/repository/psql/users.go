@@ -6,10 +6,14 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/lib/pq"
+
 	"hippo/internal/domain"
 	"hippo/internal/repository"
 )
 
+const userRolesUserFK = "user_roles_user_id_fkey"
+
 type Users struct {
 	db *sql.DB
 }
@@ -20,11 +24,18 @@ func NewUsers(db *sql.DB) *Users {
 
 func (r *Users) Create(ctx context.Context, user domain.User) error {
 	const op = "repository.psql.users.Create"
+	// the user and its initial roles are written by one statement
 	const query = `
-		INSERT INTO users (name, email, password, registered_at) 
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (email) DO NOTHING
-		RETURNING id
+		WITH created AS (
+			INSERT INTO users (name, email, password, registered_at) 
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (email) DO NOTHING
+			RETURNING id
+		), granted AS (
+			INSERT INTO user_roles (user_id, role)
+			SELECT id, unnest($5::varchar[]) FROM created
+		)
+		SELECT id FROM created
 	`
 
 	var id int64
@@ -33,6 +44,7 @@ func (r *Users) Create(ctx context.Context, user domain.User) error {
 		user.Email,
 		user.Password,
 		user.CreatedAt,
+		pq.Array(user.Roles),
 	).Scan(&id)
 
 	if errors.Is(err, sql.ErrNoRows) {
@@ -72,3 +84,66 @@ func (r *Users) GetByEmail(ctx context.Context, email string) (domain.User, erro
 		return user, nil
 	}
 }
+
+func (r *Users) GetRoles(ctx context.Context, userID int64) ([]domain.Role, error) {
+	const op = "repository.psql.users.GetRoles"
+
+	rows, err := r.db.QueryContext(ctx, "SELECT role FROM user_roles WHERE user_id = $1 ORDER BY role", userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get roles: %w", op, err)
+	}
+	defer rows.Close()
+
+	roles := make([]domain.Role, 0)
+	for rows.Next() {
+		var role domain.Role
+		if err = rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("%s: failed to scan role: %w", op, err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: error during rows iteration: %w", op, err)
+	}
+
+	return roles, nil
+}
+
+// GrantRole gives a user a role. Granting a role the user holds is a no-op.
+func (r *Users) GrantRole(ctx context.Context, userID int64, role domain.Role) error {
+	const op = "repository.psql.users.GrantRole"
+
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO user_roles (user_id, role) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		userID, role,
+	)
+	if isForeignKeyViolation(err, userRolesUserFK) {
+		return repository.NewNotFoundError(op, "user", userID)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to grant role: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokeRole takes a role away from a user. Revoking a role the user does
+// not hold is a no-op.
+func (r *Users) RevokeRole(ctx context.Context, userID int64, role domain.Role) error {
+	const op = "repository.psql.users.RevokeRole"
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists); err != nil {
+		return fmt.Errorf("%s: failed to check user: %w", op, err)
+	}
+	if !exists {
+		return repository.NewNotFoundError(op, "user", userID)
+	}
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM user_roles WHERE user_id = $1 AND role = $2", userID, role); err != nil {
+		return fmt.Errorf("%s: failed to revoke role: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/platform/logger"
+	"hippo/internal/repository"
+)
+
+const (
+	maxCategoryCodeLength = 32
+	maxCategoryNameLength = 255
+)
+
+type CategoryRepository interface {
+	Create(ctx context.Context, category domain.Category) (int64, error)
+	GetByID(ctx context.Context, id int64) (domain.Category, error)
+	List(ctx context.Context) ([]domain.Category, error)
+	Codes(ctx context.Context, codes []string) (map[string]int64, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateCategory, rev domain.MedicineRevision) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// Categories maintains the therapeutic classification tree. The audit
+// service has no entity for categories, so changes are only logged.
+type Categories struct {
+	repo      CategoryRepository
+	medicines MedicationDataRepository
+	log       logger.Logger
+}
+
+func NewCategories(repo CategoryRepository, medicines MedicationDataRepository, log logger.Logger) *Categories {
+	return &Categories{
+		repo:      repo,
+		medicines: medicines,
+		log:       log,
+	}
+}
+
+func (c *Categories) Create(ctx context.Context, category domain.Category) (domain.Category, error) {
+	code, err := normalizeCategoryCode(category.Code)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
+	name, err := normalizeCategoryName(category.Name)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
+	if category.ParentID != nil {
+		if err = c.checkParent(ctx, *category.ParentID); err != nil {
+			return domain.Category{}, err
+		}
+	}
+
+	category.Code, category.Name = code, name
+	category.CreatedAt = time.Now()
+	category.UpdatedAt = category.CreatedAt
+
+	id, err := c.repo.Create(ctx, category)
+	if err != nil {
+		return domain.Category{}, categoryError(err)
+	}
+	category.ID = id
+
+	c.log.Info("category created", logger.Int64("id", id), logger.String("code", code))
+
+	return category, nil
+}
+
+func (c *Categories) GetByID(ctx context.Context, id int64) (domain.Category, error) {
+	category, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Category{}, categoryError(err)
+	}
+	return category, nil
+}
+
+func (c *Categories) List(ctx context.Context) ([]domain.Category, error) {
+	return c.repo.List(ctx)
+}
+
+// Update renames, recodes or moves a category. A move under the category
+// itself or one of its descendants is rejected. Recoding gives the medicines
+// in the category a new version.
+func (c *Categories) Update(ctx context.Context, id int64, upd domain.UpdateCategory) (domain.Category, error) {
+	if upd.Code != nil {
+		code, err := normalizeCategoryCode(*upd.Code)
+		if err != nil {
+			return domain.Category{}, err
+		}
+		upd.Code = &code
+	}
+
+	if upd.Name != nil {
+		name, err := normalizeCategoryName(*upd.Name)
+		if err != nil {
+			return domain.Category{}, err
+		}
+		upd.Name = &name
+	}
+
+	if upd.ParentID != nil && *upd.ParentID != 0 {
+		if *upd.ParentID == id {
+			return domain.Category{}, NewValidationError("parent_id", "a category cannot be its own parent")
+		}
+		if err := c.checkParent(ctx, *upd.ParentID); err != nil {
+			return domain.Category{}, err
+		}
+	}
+
+	// a new code changes the medicines filed under the category, the
+	// repository fills in each of them and what changed on it
+	rev := newRevision(ctx, domain.RevisionUpdate, domain.Medicine{}, nil)
+
+	if err := c.repo.Update(ctx, id, upd, rev); err != nil {
+		var emptyUpdate *repository.ErrEmptyUpdate
+		if errors.As(err, &emptyUpdate) {
+			return domain.Category{}, NewValidationError("body", "no fields to update")
+		}
+		return domain.Category{}, categoryError(err)
+	}
+
+	c.log.Info("category updated", logger.Int64("id", id))
+
+	return c.GetByID(ctx, id)
+}
+
+// Delete removes a leaf category no medicine is filed under.
+func (c *Categories) Delete(ctx context.Context, id int64) error {
+	if err := c.repo.Delete(ctx, id); err != nil {
+		return categoryError(err)
+	}
+
+	c.log.Info("category deleted", logger.Int64("id", id))
+
+	return nil
+}
+
+// Medicines lists the medicines filed under a category, or anywhere in its
+// subtree when recursive is set, with the paging of the catalog listing.
+func (c *Categories) Medicines(
+	ctx context.Context,
+	id int64,
+	recursive bool,
+	opts domain.MedicineListOptions,
+) (domain.MedicinePage, error) {
+	if _, err := c.GetByID(ctx, id); err != nil {
+		return domain.MedicinePage{}, err
+	}
+
+	opts.Filter.CategoryID = id
+	opts.Filter.CategoryRecursive = recursive
+	opts, err := normalizeListOptions(opts)
+	if err != nil {
+		return domain.MedicinePage{}, err
+	}
+
+	return c.medicines.GetAll(ctx, opts)
+}
+
+func (c *Categories) checkParent(ctx context.Context, parentID int64) error {
+	_, err := c.repo.GetByID(ctx, parentID)
+
+	var repoNotFound *repository.NotFoundError
+	if errors.As(err, &repoNotFound) {
+		return NewValidationError("parent_id", "unknown category")
+	}
+	return err
+}
+
+// normalizeCategoryCode accepts codes made of letters, digits, dots and
+// dashes, which covers ATC codes as well as in-house schemes.
+func normalizeCategoryCode(code string) (string, error) {
+	code = domain.NormalizeCategoryCode(code)
+	if code == "" {
+		return "", NewValidationError("code", "cannot be empty")
+	}
+	if len(code) > maxCategoryCodeLength {
+		return "", NewValidationError("code", "is too long")
+	}
+	for _, r := range code {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-') {
+			return "", NewValidationError("code", "may only contain letters, digits, dots and dashes")
+		}
+	}
+	return code, nil
+}
+
+func normalizeCategoryName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", NewValidationError("name", "cannot be empty")
+	}
+	if len(name) > maxCategoryNameLength {
+		return "", NewValidationError("name", "is too long")
+	}
+	return name, nil
+}
+
+func categoryError(err error) error {
+	var repoNotFound *repository.NotFoundError
+	if errors.As(err, &repoNotFound) {
+		return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+	}
+
+	var duplicate *repository.ErrDuplicateCategory
+	if errors.As(err, &duplicate) {
+		return NewErrDuplicateCategory(duplicate.Code, duplicate.ExistingID, err)
+	}
+
+	var inUse *repository.ErrCategoryInUse
+	if errors.As(err, &inUse) {
+		return NewErrCategoryInUse(inUse.ID, inUse.Children, inUse.Medicines, err)
+	}
+
+	var cycle *repository.ErrCategoryCycle
+	if errors.As(err, &cycle) {
+		return NewValidationError("parent_id", "would make the category its own ancestor")
+	}
+
+	return err
+}
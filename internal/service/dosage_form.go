@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/platform/logger"
+	"hippo/internal/repository"
+)
+
+var dosageFormCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+type DosageFormRepository interface {
+	Create(ctx context.Context, form domain.DosageForm) error
+	Get(ctx context.Context, code string) (domain.DosageForm, error)
+	List(ctx context.Context) ([]domain.DosageForm, error)
+	Terms(ctx context.Context) (map[string]string, error)
+	Update(ctx context.Context, code string, upd domain.UpdateDosageForm) error
+	Delete(ctx context.Context, code string) error
+}
+
+// DosageForms maintains the form vocabulary that Medicines normalizes to.
+// The audit service has no entity for vocabulary entries, so changes are
+// only logged.
+type DosageForms struct {
+	repo DosageFormRepository
+	log  logger.Logger
+}
+
+func NewDosageForms(repo DosageFormRepository, log logger.Logger) *DosageForms {
+	return &DosageForms{
+		repo: repo,
+		log:  log,
+	}
+}
+
+func (d *DosageForms) Create(ctx context.Context, form domain.DosageForm) (domain.DosageForm, error) {
+	form.Code = strings.TrimSpace(form.Code)
+	if !dosageFormCodeRe.MatchString(form.Code) {
+		return domain.DosageForm{}, NewValidationError("code", "must be lowercase letters, digits and underscores")
+	}
+
+	form.Name = strings.TrimSpace(form.Name)
+	if form.Name == "" {
+		return domain.DosageForm{}, NewValidationError("name", "cannot be empty")
+	}
+
+	synonyms, err := normalizeSynonyms(form.Code, form.Synonyms)
+	if err != nil {
+		return domain.DosageForm{}, err
+	}
+	form.Synonyms = synonyms
+
+	form.CreatedAt = time.Now()
+	form.UpdatedAt = form.CreatedAt
+
+	if err = d.repo.Create(ctx, form); err != nil {
+		return domain.DosageForm{}, dosageFormError(err)
+	}
+
+	d.log.Info("dosage form created", logger.String("code", form.Code))
+
+	return form, nil
+}
+
+func (d *DosageForms) Get(ctx context.Context, code string) (domain.DosageForm, error) {
+	form, err := d.repo.Get(ctx, code)
+	if err != nil {
+		return domain.DosageForm{}, dosageFormError(err)
+	}
+	return form, nil
+}
+
+func (d *DosageForms) List(ctx context.Context) ([]domain.DosageForm, error) {
+	return d.repo.List(ctx)
+}
+
+// Update renames a form or replaces its synonyms. Codes cannot change, since
+// medicines store them.
+func (d *DosageForms) Update(ctx context.Context, code string, upd domain.UpdateDosageForm) (domain.DosageForm, error) {
+	if upd.Name != nil {
+		name := strings.TrimSpace(*upd.Name)
+		if name == "" {
+			return domain.DosageForm{}, NewValidationError("name", "cannot be empty")
+		}
+		upd.Name = &name
+	}
+
+	if upd.Synonyms != nil {
+		synonyms, err := normalizeSynonyms(code, *upd.Synonyms)
+		if err != nil {
+			return domain.DosageForm{}, err
+		}
+		upd.Synonyms = &synonyms
+	}
+
+	if err := d.repo.Update(ctx, code, upd); err != nil {
+		var emptyUpdate *repository.ErrEmptyUpdate
+		if errors.As(err, &emptyUpdate) {
+			return domain.DosageForm{}, NewValidationError("body", "no fields to update")
+		}
+		return domain.DosageForm{}, dosageFormError(err)
+	}
+
+	d.log.Info("dosage form updated", logger.String("code", code))
+
+	return d.Get(ctx, code)
+}
+
+func (d *DosageForms) Delete(ctx context.Context, code string) error {
+	if err := d.repo.Delete(ctx, code); err != nil {
+		return dosageFormError(err)
+	}
+
+	d.log.Info("dosage form deleted", logger.String("code", code))
+
+	return nil
+}
+
+func normalizeSynonyms(code string, synonyms []string) ([]string, error) {
+	out := make([]string, 0, len(synonyms))
+	seen := map[string]struct{}{code: {}}
+	for _, s := range synonyms {
+		term := domain.NormalizeFormTerm(s)
+		if term == "" {
+			return nil, NewValidationError("synonyms", "cannot contain empty values")
+		}
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		out = append(out, term)
+	}
+	return out, nil
+}
+
+func dosageFormError(err error) error {
+	var repoNotFound *repository.NotFoundError
+	if errors.As(err, &repoNotFound) {
+		return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+	}
+
+	var duplicate *repository.ErrDuplicateDosageForm
+	if errors.As(err, &duplicate) {
+		return NewErrDuplicateDosageForm(duplicate.Term, err)
+	}
+
+	var inUse *repository.ErrDosageFormInUse
+	if errors.As(err, &inUse) {
+		return NewErrDosageFormInUse(inUse.Code, inUse.Medicines, err)
+	}
+
+	return err
+}
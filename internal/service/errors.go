@@ -71,3 +71,212 @@ func NewErrRefreshTokenExpired() error {
 func (e *ErrRefreshTokenExpired) Error() string {
 	return fmt.Sprintf("refresh token expired")
 }
+
+// ErrRefreshTokenReused means a refresh token that had already been rotated
+// was presented again. The session it belonged to has been revoked.
+type ErrRefreshTokenReused struct {
+	UserID    int64
+	SessionID int64
+}
+
+func NewErrRefreshTokenReused(userID, sessionID int64) error {
+	return &ErrRefreshTokenReused{UserID: userID, SessionID: sessionID}
+}
+
+func (e *ErrRefreshTokenReused) Error() string {
+	return fmt.Sprintf("refresh token reused: session %d revoked", e.SessionID)
+}
+
+type ErrDuplicateNDC struct {
+	NDC        string
+	ExistingID int64
+	Cause      error
+}
+
+func NewErrDuplicateNDC(ndc string, existingID int64, cause error) error {
+	return &ErrDuplicateNDC{
+		NDC:        ndc,
+		ExistingID: existingID,
+		Cause:      cause,
+	}
+}
+
+func (e *ErrDuplicateNDC) Error() string {
+	return fmt.Sprintf("ndc already exists: %s", e.Cause)
+}
+
+type ErrVersionMismatch struct {
+	Entity   string
+	ID       interface{}
+	Expected int
+	Current  int
+	Cause    error
+}
+
+func NewErrVersionMismatch(entity string, id interface{}, expected, current int, cause error) error {
+	return &ErrVersionMismatch{
+		Entity:   entity,
+		ID:       id,
+		Expected: expected,
+		Current:  current,
+		Cause:    cause,
+	}
+}
+
+func (e *ErrVersionMismatch) Error() string {
+	return fmt.Sprintf("version mismatch: %s", e.Cause)
+}
+
+type ErrInsufficientStock struct {
+	MedicineID int64
+	Location   string
+	OnHand     int
+	Requested  int
+	Cause      error
+}
+
+func NewErrInsufficientStock(medicineID int64, location string, onHand, requested int, cause error) error {
+	return &ErrInsufficientStock{
+		MedicineID: medicineID,
+		Location:   location,
+		OnHand:     onHand,
+		Requested:  requested,
+		Cause:      cause,
+	}
+}
+
+func (e *ErrInsufficientStock) Error() string {
+	return fmt.Sprintf("%v", e.Cause)
+}
+
+type ErrDuplicateLot struct {
+	MedicineID int64
+	LotNumber  string
+	Cause      error
+}
+
+func NewErrDuplicateLot(medicineID int64, lotNumber string, cause error) error {
+	return &ErrDuplicateLot{
+		MedicineID: medicineID,
+		LotNumber:  lotNumber,
+		Cause:      cause,
+	}
+}
+
+func (e *ErrDuplicateLot) Error() string {
+	return fmt.Sprintf("lot already exists: %s", e.Cause)
+}
+
+type ErrLotInUse struct {
+	LotID int64
+	Cause error
+}
+
+func NewErrLotInUse(lotID int64, cause error) error {
+	return &ErrLotInUse{LotID: lotID, Cause: cause}
+}
+
+func (e *ErrLotInUse) Error() string {
+	return fmt.Sprintf("lot in use: %s", e.Cause)
+}
+
+type ErrDuplicateInteraction struct {
+	IngredientA string
+	IngredientB string
+	Cause       error
+}
+
+func NewErrDuplicateInteraction(ingredientA, ingredientB string, cause error) error {
+	return &ErrDuplicateInteraction{
+		IngredientA: ingredientA,
+		IngredientB: ingredientB,
+		Cause:       cause,
+	}
+}
+
+func (e *ErrDuplicateInteraction) Error() string {
+	return fmt.Sprintf("interaction already exists: %s", e.Cause)
+}
+
+type ErrDuplicateDosageForm struct {
+	Term  string
+	Cause error
+}
+
+func NewErrDuplicateDosageForm(term string, cause error) error {
+	return &ErrDuplicateDosageForm{Term: term, Cause: cause}
+}
+
+func (e *ErrDuplicateDosageForm) Error() string {
+	return fmt.Sprintf("dosage form already exists: %s", e.Cause)
+}
+
+type ErrDosageFormInUse struct {
+	Code      string
+	Medicines int
+	Cause     error
+}
+
+func NewErrDosageFormInUse(code string, medicines int, cause error) error {
+	return &ErrDosageFormInUse{Code: code, Medicines: medicines, Cause: cause}
+}
+
+func (e *ErrDosageFormInUse) Error() string {
+	return fmt.Sprintf("dosage form in use: %s", e.Cause)
+}
+
+type ErrDuplicateManufacturer struct {
+	Name       string
+	ExistingID int64
+	Cause      error
+}
+
+func NewErrDuplicateManufacturer(name string, existingID int64, cause error) error {
+	return &ErrDuplicateManufacturer{Name: name, ExistingID: existingID, Cause: cause}
+}
+
+func (e *ErrDuplicateManufacturer) Error() string {
+	return fmt.Sprintf("manufacturer already exists: %s", e.Cause)
+}
+
+type ErrManufacturerInUse struct {
+	ID    int64
+	Cause error
+}
+
+func NewErrManufacturerInUse(id int64, cause error) error {
+	return &ErrManufacturerInUse{ID: id, Cause: cause}
+}
+
+func (e *ErrManufacturerInUse) Error() string {
+	return fmt.Sprintf("manufacturer in use: %s", e.Cause)
+}
+
+type ErrDuplicateCategory struct {
+	Code       string
+	ExistingID int64
+	Cause      error
+}
+
+func NewErrDuplicateCategory(code string, existingID int64, cause error) error {
+	return &ErrDuplicateCategory{Code: code, ExistingID: existingID, Cause: cause}
+}
+
+func (e *ErrDuplicateCategory) Error() string {
+	return fmt.Sprintf("category already exists: %s", e.Cause)
+}
+
+type ErrCategoryInUse struct {
+	ID        int64
+	Children  int
+	Medicines int
+	Cause     error
+}
+
+func NewErrCategoryInUse(id int64, children, medicines int, cause error) error {
+	return &ErrCategoryInUse{ID: id, Children: children, Medicines: medicines, Cause: cause}
+}
+
+func (e *ErrCategoryInUse) Error() string {
+	return fmt.Sprintf("category in use: %s", e.Cause)
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/krez3f4l/audit_logger/pkg/domain/audit"
+
+	"hippo/internal/domain"
+)
+
+const importBatchSize = 500
+
+// MedicineRowReader yields decoded import rows and returns io.EOF when the
+// source is exhausted.
+type MedicineRowReader interface {
+	Next() (domain.ImportRow, error)
+}
+
+type importItem struct {
+	result   domain.ImportRowResult
+	medicine domain.Medicine
+}
+
+// Import validates every row with the same rules as Create and upserts the
+// accepted ones by NDC in batched transactions. A dry run classifies rows
+// without writing anything. Rows already written stay written if a later
+// batch fails.
+func (m *Medicines) Import(ctx context.Context, rows MedicineRowReader, dryRun bool) (domain.ImportReport, error) {
+	report := domain.ImportReport{
+		DryRun: dryRun,
+		Rows:   make([]domain.ImportRowResult, 0),
+	}
+
+	terms, err := m.forms.Terms(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	seen := make(map[string]int)
+	batch := make([]importItem, 0, importBatchSize)
+
+	for {
+		row, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to read import row: %w", err)
+		}
+
+		res := domain.ImportRowResult{Line: row.Line, NDC: row.Medicine.NDC}
+
+		if row.Err != nil {
+			res.Status = domain.ImportRejected
+			res.Reason = row.Err.Error()
+			report.Add(res)
+			continue
+		}
+
+		med, err := validateMedicine(row.Medicine)
+		if err == nil {
+			med.Form, err = m.normalizeForm(terms, med.Form)
+		}
+		if err == nil && med.Categories != nil {
+			med.Categories, err = m.resolveCategories(ctx, med.Categories)
+		}
+		if err != nil {
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				return report, err
+			}
+			res.Status = domain.ImportRejected
+			res.Reason = ve.Error()
+			report.Add(res)
+			continue
+		}
+		res.NDC = med.NDC
+
+		if line, ok := seen[med.NDC]; ok {
+			res.Status = domain.ImportDuplicate
+			res.Reason = fmt.Sprintf("ndc already appears on line %d", line)
+			report.Add(res)
+			continue
+		}
+		seen[med.NDC] = row.Line
+
+		batch = append(batch, importItem{result: res, medicine: med})
+		if len(batch) == importBatchSize {
+			if err = m.importBatch(ctx, batch, dryRun, &report); err != nil {
+				return report, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := m.importBatch(ctx, batch, dryRun, &report); err != nil {
+		return report, err
+	}
+
+	sort.SliceStable(report.Rows, func(i, j int) bool {
+		return report.Rows[i].Line < report.Rows[j].Line
+	})
+
+	if !dryRun && report.Created+report.Updated > 0 {
+		m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_UPDATE, 0)
+	}
+
+	return report, nil
+}
+
+func (m *Medicines) importBatch(ctx context.Context, batch []importItem, dryRun bool, report *domain.ImportReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	ndcs := make([]string, len(batch))
+	for i, item := range batch {
+		ndcs[i] = item.medicine.NDC
+	}
+
+	existing, err := m.repo.GetByNDCs(ctx, ndcs)
+	if err != nil {
+		return err
+	}
+
+	// a dry run must not create manufacturers, so unknown names stay unlinked
+	type resolved struct {
+		name string
+		id   *int64
+	}
+	manufacturers := make(map[string]resolved)
+	for i := range batch {
+		med := &batch[i].medicine
+		key := domain.NormalizeManufacturerName(med.PharmaCompany)
+
+		r, ok := manufacturers[key]
+		if !ok {
+			r.name, r.id, err = m.resolveManufacturer(ctx, med.PharmaCompany, nil, !dryRun)
+			if err != nil {
+				return err
+			}
+			manufacturers[key] = r
+		}
+		med.PharmaCompany, med.ManufacturerID = r.name, r.id
+	}
+
+	var (
+		writes  []domain.Medicine
+		revs    []domain.MedicineRevision
+		pending []int
+	)
+
+	for i := range batch {
+		item := &batch[i]
+
+		current, ok := existing[item.medicine.NDC]
+		// rows without categories, like every CSV row, keep the stored ones
+		if item.medicine.Categories == nil && ok {
+			item.medicine.Categories = current.Categories
+		}
+
+		switch {
+		case !ok:
+			item.result.Status = domain.ImportCreated
+		case len(current.Diff(item.medicine)) == 0:
+			item.result.Status = domain.ImportUnchanged
+			item.result.ID = int64(current.ID)
+			continue
+		default:
+			item.result.Status = domain.ImportUpdated
+			item.result.ID = int64(current.ID)
+			item.result.Changes = current.Diff(item.medicine)
+		}
+
+		after := item.medicine
+		if ok {
+			after.ID = current.ID
+			after.Version = current.Version + 1
+			revs = append(revs, newRevision(ctx, domain.RevisionUpdate, after, current.Diff(after)))
+		} else {
+			after.Version = 1
+			revs = append(revs, newRevision(ctx, domain.RevisionCreate, after, domain.Medicine{}.Diff(after)))
+		}
+
+		writes = append(writes, item.medicine)
+		pending = append(pending, i)
+	}
+
+	if !dryRun && len(writes) > 0 {
+		ids, err := m.repo.UpsertBatch(ctx, writes, revs)
+		if err != nil {
+			return err
+		}
+
+		for n, i := range pending {
+			batch[i].result.ID = ids[n]
+		}
+	}
+
+	for _, item := range batch {
+		report.Add(item.result)
+	}
+
+	return nil
+}
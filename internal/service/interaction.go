@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/krez3f4l/audit_logger/pkg/domain/audit"
+
+	"hippo/internal/domain"
+	"hippo/internal/platform/logger"
+	"hippo/internal/repository"
+)
+
+const (
+	maxCheckMedicines         = 50
+	maxInteractionDescription = 2000
+)
+
+type InteractionRepository interface {
+	Create(ctx context.Context, in domain.Interaction) (int64, error)
+	GetByID(ctx context.Context, id int64) (domain.Interaction, error)
+	List(ctx context.Context, ingredient string) ([]domain.Interaction, error)
+	FindAmong(ctx context.Context, ingredients []string) ([]domain.Interaction, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateInteraction) error
+	Delete(ctx context.Context, id int64) error
+	UpsertBatch(ctx context.Context, interactions []domain.Interaction) ([]int64, error)
+}
+
+// InteractionRowReader yields decoded interaction import rows and returns
+// io.EOF when the source is exhausted.
+type InteractionRowReader interface {
+	Next() (domain.InteractionImportRow, error)
+}
+
+// Interactions maintains the ingredient interaction registry. The audit
+// service has no entity for registry entries, so only checks are audited,
+// against the medicines that were checked.
+type Interactions struct {
+	repo        InteractionRepository
+	medicines   MedicationDataRepository
+	auditClient AuditClient
+	log         logger.Logger
+}
+
+func NewInteractions(
+	repo InteractionRepository,
+	medicines MedicationDataRepository,
+	auditClient AuditClient,
+	log logger.Logger,
+) *Interactions {
+	return &Interactions{
+		repo:        repo,
+		medicines:   medicines,
+		auditClient: auditClient,
+		log:         log,
+	}
+}
+
+func (i *Interactions) Create(ctx context.Context, in domain.Interaction) (domain.Interaction, error) {
+	in, err := validateInteraction(in)
+	if err != nil {
+		return domain.Interaction{}, err
+	}
+
+	in.CreatedAt = time.Now()
+	in.UpdatedAt = in.CreatedAt
+
+	id, err := i.repo.Create(ctx, in)
+	if err != nil {
+		return domain.Interaction{}, interactionError(err)
+	}
+	in.ID = id
+
+	return in, nil
+}
+
+func (i *Interactions) GetByID(ctx context.Context, id int64) (domain.Interaction, error) {
+	in, err := i.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Interaction{}, interactionError(err)
+	}
+	return in, nil
+}
+
+func (i *Interactions) List(ctx context.Context, ingredient string) ([]domain.Interaction, error) {
+	return i.repo.List(ctx, domain.NormalizeIngredient(ingredient))
+}
+
+func (i *Interactions) Update(ctx context.Context, id int64, upd domain.UpdateInteraction) (domain.Interaction, error) {
+	if upd.Severity != nil && !upd.Severity.Valid() {
+		return domain.Interaction{}, NewValidationError("severity", "must be one of minor, moderate, major, contraindicated")
+	}
+	if upd.Description != nil {
+		description := strings.TrimSpace(*upd.Description)
+		if err := validateInteractionDescription(description); err != nil {
+			return domain.Interaction{}, err
+		}
+		upd.Description = &description
+	}
+
+	if err := i.repo.Update(ctx, id, upd); err != nil {
+		var emptyUpdate *repository.ErrEmptyUpdate
+		if errors.As(err, &emptyUpdate) {
+			return domain.Interaction{}, NewValidationError("body", "no fields to update")
+		}
+		return domain.Interaction{}, interactionError(err)
+	}
+
+	return i.GetByID(ctx, id)
+}
+
+func (i *Interactions) Delete(ctx context.Context, id int64) error {
+	if err := i.repo.Delete(ctx, id); err != nil {
+		return interactionError(err)
+	}
+	return nil
+}
+
+// Check returns every registry entry that applies to a pair of the given
+// medicines. Medicines sharing an ingredient are not reported against each
+// other.
+func (i *Interactions) Check(ctx context.Context, medicineIDs []int64) (domain.InteractionCheckResult, error) {
+	ids := make([]int64, 0, len(medicineIDs))
+	seen := make(map[int64]struct{}, len(medicineIDs))
+	for _, id := range medicineIDs {
+		if id <= 0 {
+			return domain.InteractionCheckResult{}, NewValidationError("medicine_ids", "must contain positive ids")
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	switch {
+	case len(ids) < 2:
+		return domain.InteractionCheckResult{}, NewValidationError("medicine_ids", "must contain at least two distinct medicines")
+	case len(ids) > maxCheckMedicines:
+		return domain.InteractionCheckResult{}, NewValidationError("medicine_ids",
+			fmt.Sprintf("must contain at most %d medicines", maxCheckMedicines))
+	}
+
+	ingredients := make(map[int64][]string, len(ids))
+	var all []string
+	for _, id := range ids {
+		med, err := i.medicines.GetByID(ctx, id, false)
+		if err != nil {
+			return domain.InteractionCheckResult{}, interactionError(err)
+		}
+		ingredients[id] = medicineIngredients(med)
+		all = append(all, ingredients[id]...)
+	}
+
+	result := domain.InteractionCheckResult{
+		MedicineIDs:  ids,
+		Interactions: make([]domain.InteractionFinding, 0),
+	}
+
+	if len(all) > 0 {
+		known, err := i.repo.FindAmong(ctx, all)
+		if err != nil {
+			return domain.InteractionCheckResult{}, err
+		}
+
+		for x := 0; x < len(ids); x++ {
+			for y := x + 1; y < len(ids); y++ {
+				for _, in := range known {
+					if pairApplies(in, ingredients[ids[x]], ingredients[ids[y]]) {
+						result.Interactions = append(result.Interactions, domain.InteractionFinding{
+							MedicineA:   ids[x],
+							MedicineB:   ids[y],
+							Interaction: in,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	for _, id := range ids {
+		go i.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, id)
+	}
+
+	return result, nil
+}
+
+// Import validates every row and upserts the accepted ones by ingredient pair
+// in a single transaction. A dry run classifies rows without writing.
+func (i *Interactions) Import(ctx context.Context, rows InteractionRowReader, dryRun bool) (domain.InteractionImportReport, error) {
+	report := domain.InteractionImportReport{
+		DryRun: dryRun,
+		Rows:   make([]domain.InteractionImportRowResult, 0),
+	}
+
+	existing, err := i.repo.List(ctx, "")
+	if err != nil {
+		return report, err
+	}
+	known := make(map[[2]string]domain.Interaction, len(existing))
+	for _, in := range existing {
+		known[[2]string{in.IngredientA, in.IngredientB}] = in
+	}
+
+	var (
+		seen    = make(map[[2]string]int)
+		accept  []domain.Interaction
+		results []domain.InteractionImportRowResult
+		now     = time.Now()
+	)
+
+	for {
+		row, err := rows.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("failed to read import row: %w", err)
+		}
+
+		res := domain.InteractionImportRowResult{
+			Line:        row.Line,
+			IngredientA: row.Interaction.IngredientA,
+			IngredientB: row.Interaction.IngredientB,
+		}
+
+		if row.Err != nil {
+			res.Status = domain.ImportRejected
+			res.Reason = row.Err.Error()
+			report.Add(res)
+			continue
+		}
+
+		in, err := validateInteraction(row.Interaction)
+		if err != nil {
+			var ve *ValidationError
+			if !errors.As(err, &ve) {
+				return report, err
+			}
+			res.Status = domain.ImportRejected
+			res.Reason = ve.Error()
+			report.Add(res)
+			continue
+		}
+		res.IngredientA, res.IngredientB = in.IngredientA, in.IngredientB
+
+		key := [2]string{in.IngredientA, in.IngredientB}
+		if line, ok := seen[key]; ok {
+			res.Status = domain.ImportDuplicate
+			res.Reason = fmt.Sprintf("pair already appears on line %d", line)
+			report.Add(res)
+			continue
+		}
+		seen[key] = row.Line
+
+		current, ok := known[key]
+		switch {
+		case !ok:
+			res.Status = domain.ImportCreated
+		case current.Severity == in.Severity && current.Description == in.Description:
+			res.ID = current.ID
+			res.Status = domain.ImportUnchanged
+			report.Add(res)
+			continue
+		default:
+			res.ID = current.ID
+			res.Status = domain.ImportUpdated
+		}
+
+		in.CreatedAt, in.UpdatedAt = now, now
+		accept = append(accept, in)
+		results = append(results, res)
+	}
+
+	if !dryRun && len(accept) > 0 {
+		ids, err := i.repo.UpsertBatch(ctx, accept)
+		if err != nil {
+			return report, err
+		}
+		for n := range results {
+			results[n].ID = ids[n]
+		}
+	}
+
+	for _, res := range results {
+		report.Add(res)
+	}
+
+	return report, nil
+}
+
+func validateInteraction(in domain.Interaction) (domain.Interaction, error) {
+	if strings.TrimSpace(in.IngredientA) == "" {
+		return in, NewValidationError("ingredient_a", "cannot be empty")
+	}
+	if strings.TrimSpace(in.IngredientB) == "" {
+		return in, NewValidationError("ingredient_b", "cannot be empty")
+	}
+
+	in.IngredientA, in.IngredientB = domain.IngredientPair(in.IngredientA, in.IngredientB)
+	if in.IngredientA == in.IngredientB {
+		return in, NewValidationError("ingredient_b", "must differ from ingredient_a")
+	}
+
+	in.Severity = domain.InteractionSeverity(strings.ToLower(strings.TrimSpace(string(in.Severity))))
+	if !in.Severity.Valid() {
+		return in, NewValidationError("severity", "must be one of minor, moderate, major, contraindicated")
+	}
+
+	in.Description = strings.TrimSpace(in.Description)
+	if err := validateInteractionDescription(in.Description); err != nil {
+		return in, err
+	}
+
+	return in, nil
+}
+
+func validateInteractionDescription(description string) error {
+	if description == "" {
+		return NewValidationError("description", "cannot be empty")
+	}
+	if len(description) > maxInteractionDescription {
+		return NewValidationError("description", "is too long")
+	}
+	return nil
+}
+
+// medicineIngredients returns the normalized active ingredients of a medicine,
+// reading the label for rows whose ingredient list is missing.
+func medicineIngredients(med domain.Medicine) []string {
+	names := make([]string, 0, len(med.Ingredients))
+	for _, in := range med.Ingredients {
+		names = append(names, domain.NormalizeIngredient(in.Name))
+	}
+
+	if len(names) == 0 {
+		for _, name := range domain.SplitIngredients(med.ActiveIngredient) {
+			names = append(names, domain.NormalizeIngredient(name))
+		}
+	}
+
+	return names
+}
+
+func pairApplies(in domain.Interaction, a, b []string) bool {
+	return (slices.Contains(a, in.IngredientA) && slices.Contains(b, in.IngredientB)) ||
+		(slices.Contains(a, in.IngredientB) && slices.Contains(b, in.IngredientA))
+}
+
+func interactionError(err error) error {
+	var repoNotFound *repository.NotFoundError
+	if errors.As(err, &repoNotFound) {
+		return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+	}
+
+	var duplicate *repository.ErrDuplicateInteraction
+	if errors.As(err, &duplicate) {
+		return NewErrDuplicateInteraction(duplicate.IngredientA, duplicate.IngredientB, err)
+	}
+
+	return err
+}
+
+func (i *Interactions) runAuditCall(ctx context.Context, entity, action string, id int64) {
+	logErr := i.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    entity,
+		Action:    action,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+	if logErr != nil {
+		i.log.Warn("audit log failed", logger.Err(logErr))
+	}
+}
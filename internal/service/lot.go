@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/krez3f4l/audit_logger/pkg/domain/audit"
+
+	"hippo/internal/domain"
+	"hippo/internal/platform/logger"
+	"hippo/internal/repository"
+)
+
+const (
+	defaultExpiringWithin = 30 * 24 * time.Hour
+	maxLotNumberLength    = 64
+)
+
+type LotRepository interface {
+	Create(ctx context.Context, lot domain.Lot) (int64, error)
+	GetByID(ctx context.Context, id int64) (domain.Lot, error)
+	ListByMedicine(ctx context.Context, medicineID int64) ([]domain.Lot, error)
+	Expiring(ctx context.Context, before domain.Date) ([]domain.Lot, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateLot) error
+	SetStatus(ctx context.Context, ids []int64, status domain.LotStatus) ([]int64, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type Lots struct {
+	repo        LotRepository
+	medicines   MedicationDataRepository
+	auditClient AuditClient
+	log         logger.Logger
+
+	// audits tracks the audit calls still in flight, see WaitAudits
+	audits sync.WaitGroup
+}
+
+func NewLots(repo LotRepository, medicines MedicationDataRepository, auditClient AuditClient, log logger.Logger) *Lots {
+	return &Lots{
+		repo:        repo,
+		medicines:   medicines,
+		auditClient: auditClient,
+		log:         log,
+	}
+}
+
+func (l *Lots) Create(ctx context.Context, medicineID int64, lot domain.Lot) (domain.Lot, error) {
+	if _, err := l.medicines.GetByID(ctx, medicineID, false); err != nil {
+		return domain.Lot{}, lotError(err)
+	}
+
+	lot.MedicineID = medicineID
+	lot.LotNumber = strings.TrimSpace(lot.LotNumber)
+	if err := validateLotNumber(lot.LotNumber); err != nil {
+		return domain.Lot{}, err
+	}
+	if lot.ExpiresAt.IsZero() {
+		return domain.Lot{}, NewValidationError("expires_at", "is required")
+	}
+
+	lot.Status = domain.LotActive
+	lot.CreatedAt = time.Now()
+
+	id, err := l.repo.Create(ctx, lot)
+	if err != nil {
+		return domain.Lot{}, lotError(err)
+	}
+	lot.ID = id
+
+	l.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_CREATE, medicineID)
+
+	return lot, nil
+}
+
+func (l *Lots) GetByID(ctx context.Context, id int64) (domain.Lot, error) {
+	lot, err := l.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Lot{}, lotError(err)
+	}
+
+	l.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, lot.MedicineID)
+
+	return lot, nil
+}
+
+func (l *Lots) ListByMedicine(ctx context.Context, medicineID int64) ([]domain.Lot, error) {
+	if _, err := l.medicines.GetByID(ctx, medicineID, false); err != nil {
+		return nil, lotError(err)
+	}
+
+	lots, err := l.repo.ListByMedicine(ctx, medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	l.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, medicineID)
+
+	return lots, nil
+}
+
+// Expiring lists active lots that expire within the given window from today,
+// including those already past their date. A nil window means 30 days; a
+// zero window lists the expired lots only.
+func (l *Lots) Expiring(ctx context.Context, within *time.Duration) ([]domain.Lot, error) {
+	window := defaultExpiringWithin
+	if within != nil {
+		window = *within
+	}
+	if window < 0 {
+		return nil, NewValidationError("within", "cannot be negative")
+	}
+
+	return l.repo.Expiring(ctx, domain.NewDate(time.Now().Add(window)))
+}
+
+// QuarantineExpired quarantines every active lot that has expired as of now
+// and returns the lots it changed. Each change is sent to the audit log.
+func (l *Lots) QuarantineExpired(ctx context.Context, now time.Time) ([]domain.Lot, error) {
+	expired, err := l.repo.Expiring(ctx, domain.NewDate(now))
+	if err != nil {
+		return nil, err
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, 0, len(expired))
+	for _, lot := range expired {
+		ids = append(ids, lot.ID)
+	}
+
+	changed, err := l.repo.SetStatus(ctx, ids, domain.LotQuarantined)
+	if err != nil {
+		return nil, err
+	}
+
+	changedIDs := make(map[int64]struct{}, len(changed))
+	for _, id := range changed {
+		changedIDs[id] = struct{}{}
+	}
+
+	quarantined := make([]domain.Lot, 0, len(changed))
+	for _, lot := range expired {
+		if _, ok := changedIDs[lot.ID]; !ok {
+			continue
+		}
+		lot.Status = domain.LotQuarantined
+		quarantined = append(quarantined, lot)
+
+		l.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_UPDATE, lot.MedicineID)
+	}
+
+	return quarantined, nil
+}
+
+func (l *Lots) Update(ctx context.Context, id int64, upd domain.UpdateLot) (domain.Lot, error) {
+	if upd.LotNumber != nil {
+		number := strings.TrimSpace(*upd.LotNumber)
+		if err := validateLotNumber(number); err != nil {
+			return domain.Lot{}, err
+		}
+		upd.LotNumber = &number
+	}
+	if upd.ExpiresAt != nil && upd.ExpiresAt.IsZero() {
+		return domain.Lot{}, NewValidationError("expires_at", "cannot be empty")
+	}
+
+	if err := l.repo.Update(ctx, id, upd); err != nil {
+		var emptyUpdate *repository.ErrEmptyUpdate
+		if errors.As(err, &emptyUpdate) {
+			return domain.Lot{}, NewValidationError("body", "no fields to update")
+		}
+		return domain.Lot{}, lotError(err)
+	}
+
+	lot, err := l.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Lot{}, lotError(err)
+	}
+
+	l.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_UPDATE, lot.MedicineID)
+
+	return lot, nil
+}
+
+// Delete removes a lot that was registered by mistake. Lots referenced by
+// the stock ledger cannot be deleted.
+func (l *Lots) Delete(ctx context.Context, id int64) error {
+	lot, err := l.repo.GetByID(ctx, id)
+	if err != nil {
+		return lotError(err)
+	}
+
+	if err = l.repo.Delete(ctx, id); err != nil {
+		return lotError(err)
+	}
+
+	l.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_DELETE, lot.MedicineID)
+
+	return nil
+}
+
+func validateLotNumber(number string) error {
+	if number == "" {
+		return NewValidationError("lot_number", "cannot be empty")
+	}
+	if len(number) > maxLotNumberLength {
+		return NewValidationError("lot_number", "is too long")
+	}
+	return nil
+}
+
+func lotError(err error) error {
+	var repoNotFound *repository.NotFoundError
+	if errors.As(err, &repoNotFound) {
+		return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+	}
+
+	var duplicate *repository.ErrDuplicateLot
+	if errors.As(err, &duplicate) {
+		return NewErrDuplicateLot(duplicate.MedicineID, duplicate.LotNumber, err)
+	}
+
+	var inUse *repository.ErrLotInUse
+	if errors.As(err, &inUse) {
+		return NewErrLotInUse(inUse.LotID, err)
+	}
+
+	return err
+}
+
+// auditAsync sends an audit event in the background. The event outlives ctx,
+// which the expiry worker cancels on shutdown right after a scan.
+func (l *Lots) auditAsync(ctx context.Context, entity, action string, id int64) {
+	ctx = context.WithoutCancel(ctx)
+
+	l.audits.Add(1)
+	go func() {
+		defer l.audits.Done()
+		l.runAuditCall(ctx, entity, action, id)
+	}()
+}
+
+// WaitAudits blocks until the audit events sent so far are delivered. It is
+// called on shutdown once the expiry worker has stopped.
+func (l *Lots) WaitAudits() {
+	l.audits.Wait()
+}
+
+func (l *Lots) runAuditCall(ctx context.Context, entity, action string, id int64) {
+	logErr := l.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    entity,
+		Action:    action,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+	if logErr != nil {
+		l.log.Warn("audit log failed", logger.Err(logErr))
+	}
+}
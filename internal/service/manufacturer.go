@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/platform/logger"
+	"hippo/internal/repository"
+)
+
+const maxManufacturerNameLength = 255
+
+type ManufacturerRepository interface {
+	Create(ctx context.Context, manufacturer domain.Manufacturer) (int64, error)
+	GetByID(ctx context.Context, id int64) (domain.Manufacturer, error)
+	FindByName(ctx context.Context, name string) (domain.Manufacturer, error)
+	Resolve(ctx context.Context, name string) (domain.Manufacturer, error)
+	List(ctx context.Context) ([]domain.Manufacturer, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateManufacturer, rev domain.MedicineRevision) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// Manufacturers maintains the pharma companies medicines refer to. The audit
+// service has no entity for manufacturers, so changes are only logged.
+type Manufacturers struct {
+	repo      ManufacturerRepository
+	medicines MedicationDataRepository
+	log       logger.Logger
+}
+
+func NewManufacturers(repo ManufacturerRepository, medicines MedicationDataRepository, log logger.Logger) *Manufacturers {
+	return &Manufacturers{
+		repo:      repo,
+		medicines: medicines,
+		log:       log,
+	}
+}
+
+func (m *Manufacturers) Create(ctx context.Context, manufacturer domain.Manufacturer) (domain.Manufacturer, error) {
+	name, err := normalizeManufacturerName(manufacturer.Name)
+	if err != nil {
+		return domain.Manufacturer{}, err
+	}
+
+	manufacturer.Name = name
+	manufacturer.CreatedAt = time.Now()
+	manufacturer.UpdatedAt = manufacturer.CreatedAt
+
+	id, err := m.repo.Create(ctx, manufacturer)
+	if err != nil {
+		return domain.Manufacturer{}, manufacturerError(err)
+	}
+	manufacturer.ID = id
+
+	m.log.Info("manufacturer created", logger.Int64("id", id))
+
+	return manufacturer, nil
+}
+
+func (m *Manufacturers) GetByID(ctx context.Context, id int64) (domain.Manufacturer, error) {
+	manufacturer, err := m.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Manufacturer{}, manufacturerError(err)
+	}
+	return manufacturer, nil
+}
+
+func (m *Manufacturers) List(ctx context.Context) ([]domain.Manufacturer, error) {
+	return m.repo.List(ctx)
+}
+
+// Update renames a manufacturer. Its medicines pick up the new name.
+func (m *Manufacturers) Update(ctx context.Context, id int64, upd domain.UpdateManufacturer) (domain.Manufacturer, error) {
+	if upd.Name != nil {
+		name, err := normalizeManufacturerName(*upd.Name)
+		if err != nil {
+			return domain.Manufacturer{}, err
+		}
+		upd.Name = &name
+	}
+
+	// the repository fills in each renamed medicine and what changed on it
+	rev := newRevision(ctx, domain.RevisionUpdate, domain.Medicine{}, nil)
+
+	if err := m.repo.Update(ctx, id, upd, rev); err != nil {
+		var emptyUpdate *repository.ErrEmptyUpdate
+		if errors.As(err, &emptyUpdate) {
+			return domain.Manufacturer{}, NewValidationError("body", "no fields to update")
+		}
+		return domain.Manufacturer{}, manufacturerError(err)
+	}
+
+	m.log.Info("manufacturer updated", logger.Int64("id", id))
+
+	return m.GetByID(ctx, id)
+}
+
+// Delete removes a manufacturer no medicine refers to.
+func (m *Manufacturers) Delete(ctx context.Context, id int64) error {
+	if err := m.repo.Delete(ctx, id); err != nil {
+		return manufacturerError(err)
+	}
+
+	m.log.Info("manufacturer deleted", logger.Int64("id", id))
+
+	return nil
+}
+
+// Medicines lists the medicines of a manufacturer with the same paging and
+// sorting as the catalog listing.
+func (m *Manufacturers) Medicines(ctx context.Context, id int64, opts domain.MedicineListOptions) (domain.MedicinePage, error) {
+	if _, err := m.GetByID(ctx, id); err != nil {
+		return domain.MedicinePage{}, err
+	}
+
+	opts.Filter.ManufacturerID = id
+	opts, err := normalizeListOptions(opts)
+	if err != nil {
+		return domain.MedicinePage{}, err
+	}
+
+	return m.medicines.GetAll(ctx, opts)
+}
+
+func normalizeManufacturerName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", NewValidationError("name", "cannot be empty")
+	}
+	if len(name) > maxManufacturerNameLength {
+		return "", NewValidationError("name", "is too long")
+	}
+	return name, nil
+}
+
+func manufacturerError(err error) error {
+	var repoNotFound *repository.NotFoundError
+	if errors.As(err, &repoNotFound) {
+		return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+	}
+
+	var duplicate *repository.ErrDuplicateManufacturer
+	if errors.As(err, &duplicate) {
+		return NewErrDuplicateManufacturer(duplicate.Name, duplicate.ExistingID, err)
+	}
+
+	var inUse *repository.ErrManufacturerInUse
+	if errors.As(err, &inUse) {
+		return NewErrManufacturerInUse(inUse.ID, err)
+	}
+
+	return err
+}
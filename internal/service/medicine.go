@@ -3,7 +3,11 @@ package service
 import (
 	"context"
 	"errors"
-	"regexp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	//my 2nd github acc :)
@@ -12,69 +16,238 @@ import (
 	"hippo/internal/domain"
 	"hippo/internal/platform/logger"
 	"hippo/internal/repository"
+	"hippo/pkg/ndc"
+	"hippo/pkg/strength"
 )
 
 type MedicationDataRepository interface {
-	Create(ctx context.Context, medicament domain.Medicine) (int64, error)
-	GetAll(ctx context.Context) ([]domain.Medicine, error)
-	GetByID(ctx context.Context, id int64) (domain.Medicine, error)
-	Update(ctx context.Context, id int64, upd domain.UpdateMedicine) error
-	Delete(ctx context.Context, id int64) error
+	Create(ctx context.Context, medicament domain.Medicine, rev domain.MedicineRevision) (int64, error)
+	GetAll(ctx context.Context, opts domain.MedicineListOptions) (domain.MedicinePage, error)
+	GetByID(ctx context.Context, id int64, includeDeleted bool) (domain.Medicine, error)
+	GetByNDC(ctx context.Context, ndc string, includeDeleted bool) (domain.Medicine, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateMedicine, ifVersion int, rev *domain.MedicineRevision) error
+	Delete(ctx context.Context, id int64, ifVersion int, rev domain.MedicineRevision) error
+	Restore(ctx context.Context, id int64, rev domain.MedicineRevision) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	Search(ctx context.Context, query string, limit int) ([]domain.MedicineSearchResult, error)
+	GetByNDCs(ctx context.Context, ndcs []string) (map[string]domain.Medicine, error)
+	UpsertBatch(ctx context.Context, medicines []domain.Medicine, revs []domain.MedicineRevision) ([]int64, error)
+	Export(ctx context.Context, opts domain.MedicineListOptions, fn func(domain.Medicine) error) error
 }
 
-const ndcPattern = `^\d{5}-\d{4}-\d{2}$`
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+
+	// maxWriteAttempts bounds retries of writes that lost a race to a
+	// concurrent change of the same medicine
+	maxWriteAttempts = 3
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+	minSearchQueryLen  = 2
+)
+
+// DosageFormVocabulary maps form codes and synonyms to canonical codes.
+type DosageFormVocabulary interface {
+	Terms(ctx context.Context) (map[string]string, error)
+}
+
+// ManufacturerResolver finds the manufacturer rows behind pharma company names.
+type ManufacturerResolver interface {
+	GetByID(ctx context.Context, id int64) (domain.Manufacturer, error)
+	FindByName(ctx context.Context, name string) (domain.Manufacturer, error)
+	Resolve(ctx context.Context, name string) (domain.Manufacturer, error)
+}
+
+// CategoryCatalog tells which category codes exist.
+type CategoryCatalog interface {
+	Codes(ctx context.Context, codes []string) (map[string]int64, error)
+}
 
 type Medicines struct {
-	repo        MedicationDataRepository
-	auditClient AuditClient
-	log         logger.Logger
+	repo          MedicationDataRepository
+	revisions     MedicineRevisionRepository
+	forms         DosageFormVocabulary
+	manufacturers ManufacturerResolver
+	categories    CategoryCatalog
+	auditClient   AuditClient
+	log           logger.Logger
+
+	// deletedRetention is how long tombstones survive before Purge removes them
+	deletedRetention time.Duration
+	// strictForms rejects forms missing from the vocabulary instead of
+	// storing them as given
+	strictForms bool
 
-	ndcMask *regexp.Regexp
+	// audits tracks the audit calls still in flight, see WaitAudits
+	audits sync.WaitGroup
 }
 
-func NewMedicines(repo MedicationDataRepository, auditClient AuditClient, log logger.Logger) *Medicines {
+func NewMedicines(
+	repo MedicationDataRepository,
+	revisions MedicineRevisionRepository,
+	forms DosageFormVocabulary,
+	manufacturers ManufacturerResolver,
+	categories CategoryCatalog,
+	auditClient AuditClient,
+	log logger.Logger,
+	deletedRetention time.Duration,
+	strictForms bool,
+) *Medicines {
 	return &Medicines{
-		repo:        repo,
-		auditClient: auditClient,
-		log:         log,
-		ndcMask:     regexp.MustCompile(ndcPattern),
+		repo:             repo,
+		revisions:        revisions,
+		forms:            forms,
+		manufacturers:    manufacturers,
+		categories:       categories,
+		auditClient:      auditClient,
+		log:              log,
+		deletedRetention: deletedRetention,
+		strictForms:      strictForms,
 	}
 }
 
 func (m *Medicines) Create(ctx context.Context, medicament domain.Medicine) (int64, error) {
-	/// TODO  business logic
-	// TODO merge and take out the logic if it becomes large
-	if medicament.Name == "" {
-		return -1, NewValidationError("name", "cannot be empty")
+	medicament, err := validateMedicine(medicament)
+	if err != nil {
+		return -1, err
 	}
 
-	if !m.ndcMask.MatchString(medicament.NDC) {
-		return -1, NewValidationError("ndc", "invalid NDC format")
+	terms, err := m.forms.Terms(ctx)
+	if err != nil {
+		return -1, err
+	}
+	if medicament.Form, err = m.normalizeForm(terms, medicament.Form); err != nil {
+		return -1, err
 	}
 
-	id, err := m.repo.Create(ctx, medicament)
+	medicament.PharmaCompany, medicament.ManufacturerID, err = m.resolveManufacturer(ctx,
+		medicament.PharmaCompany, medicament.ManufacturerID, true)
 	if err != nil {
 		return -1, err
 	}
 
-	go m.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_CREATE, 0)
+	if medicament.Categories, err = m.resolveCategories(ctx, medicament.Categories); err != nil {
+		return -1, err
+	}
+
+	created := medicament
+	created.Version = 1
+	rev := newRevision(ctx, domain.RevisionCreate, created, domain.Medicine{}.Diff(created))
+
+	id, err := m.repo.Create(ctx, medicament, rev)
+	if err != nil {
+		var duplicateNDC *repository.ErrDuplicateNDC
+		if errors.As(err, &duplicateNDC) {
+			return -1, NewErrDuplicateNDC(duplicateNDC.NDC, duplicateNDC.ExistingID, err)
+		}
+		return -1, err
+	}
+
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_CREATE, 0)
 
 	return id, nil
 }
 
-func (m *Medicines) GetAll(ctx context.Context) ([]domain.Medicine, error) {
-	medicines, err := m.repo.GetAll(ctx)
+func (m *Medicines) GetAll(ctx context.Context, opts domain.MedicineListOptions) (domain.MedicinePage, error) {
+	opts, err := normalizeListOptions(opts)
+	if err != nil {
+		return domain.MedicinePage{}, err
+	}
+
+	page, err := m.repo.GetAll(ctx, opts)
+	if err != nil {
+		return domain.MedicinePage{}, err
+	}
+
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, 0)
+
+	return page, nil
+}
+
+// Export streams all medicines matching the listing filters to fn. Limit and
+// cursor are ignored. One audit event is sent for the whole export.
+func (m *Medicines) Export(ctx context.Context, opts domain.MedicineListOptions, fn func(domain.Medicine) error) error {
+	opts.Limit, opts.After = 0, nil
+
+	opts, err := normalizeListOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	if err = m.repo.Export(ctx, opts, fn); err != nil {
+		return err
+	}
+
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, 0)
+
+	return nil
+}
+
+func normalizeListOptions(opts domain.MedicineListOptions) (domain.MedicineListOptions, error) {
+	if opts.Filter.NDC != "" {
+		code, err := normalizeNDC(opts.Filter.NDC)
+		if err != nil {
+			return opts, err
+		}
+		opts.Filter.NDC = code
+	}
+
+	// catalog names are stored single-spaced
+	opts.Filter.Ingredient = strings.Join(strings.Fields(opts.Filter.Ingredient), " ")
+
+	switch {
+	case opts.Limit == 0:
+		opts.Limit = defaultPageLimit
+	case opts.Limit < 0 || opts.Limit > maxPageLimit:
+		return opts, NewValidationError("limit", fmt.Sprintf("must be between 1 and %d", maxPageLimit))
+	}
+
+	switch opts.SortBy {
+	case "":
+		opts.SortBy = domain.MedicineSortByID
+	case domain.MedicineSortByID, domain.MedicineSortByName:
+	default:
+		return opts, NewValidationError("sort", "must be one of: id, name")
+	}
+
+	switch opts.Order {
+	case "":
+		opts.Order = domain.SortAsc
+	case domain.SortAsc, domain.SortDesc:
+	default:
+		return opts, NewValidationError("order", "must be one of: asc, desc")
+	}
+
+	return opts, nil
+}
+
+func (m *Medicines) Search(ctx context.Context, query string, limit int) ([]domain.MedicineSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if len([]rune(query)) < minSearchQueryLen {
+		return nil, NewValidationError("q", fmt.Sprintf("must be at least %d characters", minSearchQueryLen))
+	}
+
+	switch {
+	case limit == 0:
+		limit = defaultSearchLimit
+	case limit < 0 || limit > maxSearchLimit:
+		return nil, NewValidationError("limit", fmt.Sprintf("must be between 1 and %d", maxSearchLimit))
+	}
+
+	results, err := m.repo.Search(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	go m.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, 0)
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, 0)
 
-	return medicines, nil
+	return results, nil
 }
 
-func (m *Medicines) GetByID(ctx context.Context, id int64) (domain.Medicine, error) {
-	medicine, err := m.repo.GetByID(ctx, id)
+func (m *Medicines) GetByID(ctx context.Context, id int64, includeDeleted bool) (domain.Medicine, error) {
+	medicine, err := m.repo.GetByID(ctx, id, includeDeleted)
 	if err != nil {
 		var repoNotFound *repository.NotFoundError
 		if errors.As(err, &repoNotFound) {
@@ -83,54 +256,618 @@ func (m *Medicines) GetByID(ctx context.Context, id int64) (domain.Medicine, err
 		return domain.Medicine{}, err
 	}
 
-	go m.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, id)
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, id)
 
 	return medicine, nil
 }
 
-func (m *Medicines) Update(ctx context.Context, id int64, med domain.UpdateMedicine) error {
+func (m *Medicines) GetByNDC(ctx context.Context, rawNDC string, includeDeleted bool) (domain.Medicine, error) {
+	code, err := normalizeNDC(rawNDC)
+	if err != nil {
+		return domain.Medicine{}, err
+	}
+
+	medicine, err := m.repo.GetByNDC(ctx, code, includeDeleted)
+	if err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return domain.Medicine{}, NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+		return domain.Medicine{}, err
+	}
+
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, int64(medicine.ID))
+
+	return medicine, nil
+}
+
+// Update changes a medicine and returns it as stored. A non-zero ifVersion
+// is the version the caller last saw; the update is refused with
+// ErrVersionMismatch if it has moved on.
+func (m *Medicines) Update(ctx context.Context, id int64, med domain.UpdateMedicine, ifVersion int) (domain.Medicine, error) {
+	if err := m.update(ctx, id, med, ifVersion, domain.RevisionUpdate); err != nil {
+		return domain.Medicine{}, err
+	}
+
+	medicine, err := m.repo.GetByID(ctx, id, false)
+	if err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return domain.Medicine{}, NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+		return domain.Medicine{}, err
+	}
+
+	return medicine, nil
+}
+
+func (m *Medicines) update(
+	ctx context.Context,
+	id int64,
+	med domain.UpdateMedicine,
+	ifVersion int,
+	action domain.RevisionAction,
+) error {
 	if med.NDC != nil {
-		if !m.ndcMask.MatchString(*med.NDC) {
-			return NewValidationError("ndc", "invalid NDC format")
+		code, err := normalizeNDC(*med.NDC)
+		if err != nil {
+			return err
+		}
+		med.NDC = &code
+	}
+
+	if med.Dosage != nil || med.Strength != nil {
+		var dosage string
+		if med.Dosage != nil {
+			dosage = *med.Dosage
+		}
+
+		// a revert may restore a dosage recorded before strengths were validated
+		canonical, _, err := normalizeDosage(dosage, med.Strength)
+		switch {
+		case err == nil:
+			med.Dosage, med.Strength = &canonical, nil
+		case action != domain.RevisionRevert:
+			return err
+		}
+	}
+
+	if med.Form != nil {
+		terms, err := m.forms.Terms(ctx)
+		if err != nil {
+			return err
+		}
+
+		form, err := m.normalizeForm(terms, *med.Form)
+		switch {
+		case err == nil:
+			med.Form = &form
+		case action != domain.RevisionRevert:
+			return err
+		}
+	}
+
+	if med.PharmaCompany != nil || med.ManufacturerID != nil {
+		var company string
+		if med.PharmaCompany != nil {
+			company = *med.PharmaCompany
+		}
+
+		name, manufacturerID, err := m.resolveManufacturer(ctx, company, med.ManufacturerID, true)
+		if err != nil {
+			return err
+		}
+
+		var idValue int64
+		if manufacturerID != nil {
+			idValue = *manufacturerID
+		}
+		med.PharmaCompany, med.ManufacturerID = &name, &idValue
+	}
+
+	if med.Categories != nil {
+		// a revert may name categories deleted since, they are left out
+		codes, err := m.resolveCategories(ctx, *med.Categories)
+		switch {
+		case err == nil:
+			med.Categories = &codes
+		case action != domain.RevisionRevert:
+			return err
+		}
+	}
+
+	// without ifVersion the write is still pinned to the version it was
+	// computed from, so the recorded revision cannot miss a concurrent
+	// update; a lost race is retried on fresh data
+	for attempt := 1; ; attempt++ {
+		err := m.applyUpdate(ctx, id, med, ifVersion, action)
+
+		var versionMismatch *ErrVersionMismatch
+		if ifVersion > 0 || attempt == maxWriteAttempts || !errors.As(err, &versionMismatch) {
+			return err
+		}
+	}
+}
+
+// applyUpdate writes one attempt of update against the current row.
+func (m *Medicines) applyUpdate(
+	ctx context.Context,
+	id int64,
+	med domain.UpdateMedicine,
+	ifVersion int,
+	action domain.RevisionAction,
+) error {
+	before, err := m.repo.GetByID(ctx, id, false)
+	if err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+		return err
+	}
+
+	if ifVersion > 0 && before.Version != ifVersion {
+		return NewErrVersionMismatch("medicine", id, ifVersion, before.Version,
+			fmt.Errorf("medicine with ID %d has version %d", id, before.Version))
+	}
+
+	if med.ActiveIngredient != nil || med.Ingredients != nil || (med.Dosage != nil && len(before.Ingredients) == 1) {
+		target := before.Apply(med)
+
+		var given []domain.MedicineIngredient
+		switch {
+		case med.Ingredients != nil:
+			given = *med.Ingredients
+		case med.ActiveIngredient == nil:
+			// only the dosage changed, a single ingredient follows it
+			given = []domain.MedicineIngredient{{Name: before.Ingredients[0].Name}}
 		}
+
+		label, ingredients, err := normalizeIngredients(target.ActiveIngredient, given, target.Strength)
+		switch {
+		case err == nil:
+			med.ActiveIngredient, med.Ingredients = &label, &ingredients
+		case action != domain.RevisionRevert:
+			return err
+		}
+	}
+
+	after := before.Apply(med)
+	after.Version++
+
+	var rev *domain.MedicineRevision
+	if changes := before.Diff(after); len(changes) > 0 {
+		r := newRevision(ctx, action, after, changes)
+		rev = &r
 	}
 
-	err := m.repo.Update(ctx, id, med)
+	err = m.repo.Update(ctx, id, med, before.Version, rev)
 	if err != nil {
 		var repoNotFound *repository.NotFoundError
 		if errors.As(err, &repoNotFound) {
 			return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
 		}
 
+		var versionMismatch *repository.ErrVersionMismatch
+		if errors.As(err, &versionMismatch) {
+			return NewErrVersionMismatch(versionMismatch.Entity, versionMismatch.ID,
+				versionMismatch.Expected, versionMismatch.Current, err)
+		}
+
 		var emptyUpdate *repository.ErrEmptyUpdate
 		if errors.As(err, &emptyUpdate) {
 			return nil
 		}
 
+		var duplicateNDC *repository.ErrDuplicateNDC
+		if errors.As(err, &duplicateNDC) {
+			return NewErrDuplicateNDC(duplicateNDC.NDC, duplicateNDC.ExistingID, err)
+		}
+
+		return err
+	}
+
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_UPDATE, id)
+
+	return nil
+}
+
+// Delete tombstones a medicine, honoring ifVersion the same way Update does.
+func (m *Medicines) Delete(ctx context.Context, id int64, ifVersion int) error {
+	for attempt := 1; ; attempt++ {
+		err := m.applyDelete(ctx, id, ifVersion)
+
+		var versionMismatch *ErrVersionMismatch
+		if ifVersion > 0 || attempt == maxWriteAttempts || !errors.As(err, &versionMismatch) {
+			return err
+		}
+	}
+}
+
+// applyDelete writes one attempt of Delete against the current row.
+func (m *Medicines) applyDelete(ctx context.Context, id int64, ifVersion int) error {
+	before, err := m.repo.GetByID(ctx, id, false)
+	if err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
 		return err
 	}
 
-	go m.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_UPDATE, id)
+	if ifVersion > 0 && before.Version != ifVersion {
+		return NewErrVersionMismatch("medicine", id, ifVersion, before.Version,
+			fmt.Errorf("medicine with ID %d has version %d", id, before.Version))
+	}
+
+	err = m.repo.Delete(ctx, id, before.Version, newRevision(ctx, domain.RevisionDelete, before, nil))
+	if err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+
+		var versionMismatch *repository.ErrVersionMismatch
+		if errors.As(err, &versionMismatch) {
+			return NewErrVersionMismatch(versionMismatch.Entity, versionMismatch.ID,
+				versionMismatch.Expected, versionMismatch.Current, err)
+		}
+
+		return err
+	}
+
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_DELETE, id)
 
 	return nil
 }
 
-func (m *Medicines) Delete(ctx context.Context, id int64) error {
-	err := m.repo.Delete(ctx, id)
+func (m *Medicines) Restore(ctx context.Context, id int64) error {
+	err := m.repo.Restore(ctx, id, newRevision(ctx, domain.RevisionRestore, domain.Medicine{}, nil))
 	if err != nil {
 		var repoNotFound *repository.NotFoundError
 		if errors.As(err, &repoNotFound) {
 			return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
 		}
 
+		var duplicateNDC *repository.ErrDuplicateNDC
+		if errors.As(err, &duplicateNDC) {
+			return NewErrDuplicateNDC(duplicateNDC.NDC, duplicateNDC.ExistingID, err)
+		}
+
 		return err
 	}
 
-	go m.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_DELETE, id)
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_UPDATE, id)
 
 	return nil
 }
 
+// Purge physically removes medicines that were deleted longer than the
+// retention window ago and returns how many rows are gone.
+func (m *Medicines) Purge(ctx context.Context) (int64, error) {
+	purged, err := m.repo.Purge(ctx, time.Now().Add(-m.deletedRetention))
+	if err != nil {
+		return 0, err
+	}
+
+	m.log.Info("purged deleted medicines", logger.Int("count", int(purged)))
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_DELETE, 0)
+
+	return purged, nil
+}
+
+// validateMedicine applies the catalog rules to a new medicine and returns
+// it in its stored form.
+func validateMedicine(medicament domain.Medicine) (domain.Medicine, error) {
+	if medicament.Name == "" {
+		return medicament, NewValidationError("name", "cannot be empty")
+	}
+
+	code, err := normalizeNDC(medicament.NDC)
+	if err != nil {
+		return medicament, err
+	}
+	medicament.NDC = code
+
+	medicament.Dosage, medicament.Strength, err = normalizeDosage(medicament.Dosage, medicament.Strength)
+	if err != nil {
+		return medicament, err
+	}
+
+	medicament.ActiveIngredient, medicament.Ingredients, err = normalizeIngredients(
+		medicament.ActiveIngredient, medicament.Ingredients, medicament.Strength)
+	if err != nil {
+		return medicament, err
+	}
+
+	return medicament, nil
+}
+
+// normalizeDosage reconciles the dosage text with a structured strength and
+// returns the canonical text with its parsed form. Either may be given; if
+// both are, they must describe the same strength. Text the parser does not
+// understand, such as "1%", is kept as given without a strength.
+func normalizeDosage(dosage string, st *strength.Strength) (string, *strength.Strength, error) {
+	dosage = strings.TrimSpace(dosage)
+
+	var parsed *strength.Strength
+	if dosage != "" {
+		if p, err := strength.Parse(dosage); err == nil {
+			parsed = &p
+		}
+	}
+
+	if st != nil {
+		if err := st.Validate(); err != nil {
+			return "", nil, NewValidationError("strength", err.Error())
+		}
+		if dosage != "" && (parsed == nil || parsed.String() != st.String()) {
+			return "", nil, NewValidationError("dosage", "does not match strength")
+		}
+		parsed = st
+	}
+
+	if parsed == nil {
+		return dosage, nil, nil
+	}
+
+	return parsed.String(), parsed, nil
+}
+
+// normalizeIngredients reconciles the active ingredient label with the
+// ingredient list and returns both in stored form. A given list is
+// authoritative and the label is rebuilt from it; otherwise the list is
+// derived from the label. A lone ingredient without its own strength takes
+// the strength of the medicine.
+func normalizeIngredients(
+	label string,
+	ingredients []domain.MedicineIngredient,
+	st *strength.Strength,
+) (string, []domain.MedicineIngredient, error) {
+	label = strings.TrimSpace(label)
+
+	given := len(ingredients) > 0
+	if !given {
+		for _, name := range domain.SplitIngredients(label) {
+			ingredients = append(ingredients, domain.MedicineIngredient{Name: name})
+		}
+	}
+
+	normalized := make([]domain.MedicineIngredient, len(ingredients))
+	names := make([]string, len(ingredients))
+	seen := make(map[string]bool, len(ingredients))
+
+	for i, in := range ingredients {
+		name := strings.Join(strings.Fields(in.Name), " ")
+		if name == "" {
+			return "", nil, NewValidationError("ingredients", fmt.Sprintf("ingredient %d has no name", i+1))
+		}
+		if strings.ContainsAny(name, "/+") {
+			return "", nil, NewValidationError("ingredients", fmt.Sprintf("%q must name a single ingredient", name))
+		}
+
+		key := domain.NormalizeIngredient(name)
+		if seen[key] {
+			return "", nil, NewValidationError("ingredients", fmt.Sprintf("%q is listed more than once", name))
+		}
+		seen[key] = true
+
+		if in.Strength != nil {
+			if err := in.Strength.Validate(); err != nil {
+				return "", nil, NewValidationError("ingredients", fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+
+		normalized[i] = domain.MedicineIngredient{Name: name, Strength: in.Strength}
+		names[i] = name
+	}
+
+	if len(normalized) == 1 && normalized[0].Strength == nil {
+		normalized[0].Strength = st
+	}
+
+	if given {
+		label = strings.Join(names, "/")
+	}
+
+	return label, normalized, nil
+}
+
+// resolveCategories normalizes category codes and rejects unknown ones. The
+// result is sorted and free of duplicates.
+func (m *Medicines) resolveCategories(ctx context.Context, codes []string) ([]string, error) {
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = domain.NormalizeCategoryCode(code)
+		if code == "" {
+			return nil, NewValidationError("categories", "codes cannot be empty")
+		}
+		normalized = append(normalized, code)
+	}
+	sort.Strings(normalized)
+	normalized = slices.Compact(normalized)
+
+	if len(normalized) == 0 {
+		return normalized, nil
+	}
+
+	known, err := m.categories.Codes(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	var unknown []string
+	for _, code := range normalized {
+		if _, ok := known[code]; !ok {
+			unknown = append(unknown, code)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, NewValidationError("categories", "unknown category codes: "+strings.Join(unknown, ", "))
+	}
+
+	return normalized, nil
+}
+
+// normalizeForm maps a form to its canonical code. Unknown forms are kept as
+// given, or rejected in strict mode.
+func (m *Medicines) normalizeForm(terms map[string]string, form string) (string, error) {
+	form = strings.TrimSpace(form)
+	if form == "" {
+		return "", nil
+	}
+
+	if code, ok := terms[domain.NormalizeFormTerm(form)]; ok {
+		return code, nil
+	}
+
+	if m.strictForms {
+		return "", NewValidationError("form", "unknown dosage form")
+	}
+
+	return form, nil
+}
+
+// resolveManufacturer links a medicine to its manufacturer. An explicit id
+// wins and its name replaces the pharma company text; otherwise the text is
+// looked up by name and, if create is set, a manufacturer is added for it.
+func (m *Medicines) resolveManufacturer(
+	ctx context.Context,
+	company string,
+	manufacturerID *int64,
+	create bool,
+) (string, *int64, error) {
+	company = strings.Join(strings.Fields(company), " ")
+
+	if manufacturerID != nil && *manufacturerID > 0 {
+		manufacturer, err := m.manufacturers.GetByID(ctx, *manufacturerID)
+		if err != nil {
+			var repoNotFound *repository.NotFoundError
+			if errors.As(err, &repoNotFound) {
+				return "", nil, NewValidationError("manufacturer_id", "unknown manufacturer")
+			}
+			return "", nil, err
+		}
+
+		if company != "" && domain.NormalizeManufacturerName(company) != domain.NormalizeManufacturerName(manufacturer.Name) {
+			return "", nil, NewValidationError("pharma_company", "does not match manufacturer_id")
+		}
+
+		return manufacturer.Name, &manufacturer.ID, nil
+	}
+
+	if company == "" {
+		return "", nil, nil
+	}
+
+	if !create {
+		manufacturer, err := m.manufacturers.FindByName(ctx, company)
+		if err != nil {
+			var repoNotFound *repository.NotFoundError
+			if errors.As(err, &repoNotFound) {
+				return company, nil, nil
+			}
+			return "", nil, err
+		}
+		return manufacturer.Name, &manufacturer.ID, nil
+	}
+
+	manufacturer, err := m.manufacturers.Resolve(ctx, company)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return manufacturer.Name, &manufacturer.ID, nil
+}
+
+// BackfillForms rewrites the form of every live medicine to its canonical
+// code through the regular update path, so each change gets a revision.
+// Forms missing from the vocabulary are counted but left alone.
+func (m *Medicines) BackfillForms(ctx context.Context, dryRun bool) (domain.FormBackfillReport, error) {
+	report := domain.FormBackfillReport{
+		DryRun:  dryRun,
+		Unknown: make(map[string]int),
+		Changes: make([]domain.FormChange, 0),
+	}
+
+	terms, err := m.forms.Terms(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	err = m.repo.Export(ctx, domain.MedicineListOptions{SortBy: domain.MedicineSortByID, Order: domain.SortAsc},
+		func(med domain.Medicine) error {
+			report.Scanned++
+			if strings.TrimSpace(med.Form) == "" {
+				return nil
+			}
+
+			code, ok := terms[domain.NormalizeFormTerm(med.Form)]
+			switch {
+			case !ok:
+				report.Unknown[med.Form]++
+			case code != med.Form:
+				report.Changes = append(report.Changes, domain.FormChange{
+					MedicineID: int64(med.ID),
+					From:       med.Form,
+					To:         code,
+				})
+			}
+			return nil
+		})
+	if err != nil {
+		return report, err
+	}
+
+	if dryRun {
+		report.Changed = len(report.Changes)
+		return report, nil
+	}
+
+	for _, c := range report.Changes {
+		code := c.To
+		if err = m.update(ctx, c.MedicineID, domain.UpdateMedicine{Form: &code}, 0, domain.RevisionUpdate); err != nil {
+			report.Failed++
+			m.log.Warn("form backfill failed",
+				logger.Int64("medicine_id", c.MedicineID),
+				logger.Err(err),
+			)
+			continue
+		}
+		report.Changed++
+	}
+
+	return report, nil
+}
+
+// normalizeNDC converts any FDA layout to the stored 5-4-2 form.
+func normalizeNDC(raw string) (string, error) {
+	code, err := ndc.Normalize(raw)
+	switch {
+	case errors.Is(err, ndc.ErrAmbiguous):
+		return "", NewValidationError("ndc", "ambiguous NDC, use a hyphenated 4-4-2, 5-3-2 or 5-4-1 layout")
+	case err != nil:
+		return "", NewValidationError("ndc", "invalid NDC format")
+	}
+
+	return code, nil
+}
+
+// auditAsync sends an audit event in the background.
+func (m *Medicines) auditAsync(ctx context.Context, entity, action string, id int64) {
+	m.audits.Add(1)
+	go func() {
+		defer m.audits.Done()
+		m.runAuditCall(ctx, entity, action, id)
+	}()
+}
+
+// WaitAudits blocks until the audit events sent so far are delivered. One-off
+// commands call it before the process exits.
+func (m *Medicines) WaitAudits() {
+	m.audits.Wait()
+}
+
 func (m *Medicines) runAuditCall(ctx context.Context, entity, action string, id int64) {
 	logErr := m.auditClient.SendLogRequest(ctx, audit.LogItem{
 		Entity:    entity,
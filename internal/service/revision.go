@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/krez3f4l/audit_logger/pkg/domain/audit"
+
+	"hippo/internal/domain"
+	"hippo/internal/repository"
+)
+
+type MedicineRevisionRepository interface {
+	List(ctx context.Context, medicineID int64) ([]domain.MedicineRevision, error)
+	Get(ctx context.Context, medicineID int64, revision int) (domain.MedicineRevision, error)
+}
+
+func (m *Medicines) History(ctx context.Context, id int64) ([]domain.MedicineRevision, error) {
+	if _, err := m.repo.GetByID(ctx, id, true); err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return nil, NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+		return nil, err
+	}
+
+	revisions, err := m.revisions.List(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m.auditAsync(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, id)
+
+	return revisions, nil
+}
+
+// Revert brings a live medicine back to the state captured by revision.
+// The revert itself is recorded as a new revision.
+func (m *Medicines) Revert(ctx context.Context, id int64, revision int) (domain.Medicine, error) {
+	rev, err := m.revisions.Get(ctx, id, revision)
+	if err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return domain.Medicine{}, NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+		return domain.Medicine{}, err
+	}
+
+	current, err := m.repo.GetByID(ctx, id, false)
+	if err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return domain.Medicine{}, NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+		return domain.Medicine{}, err
+	}
+
+	upd := current.UpdateTo(rev.Snapshot)
+	if upd == (domain.UpdateMedicine{}) {
+		return current, nil
+	}
+
+	if err = m.update(ctx, id, upd, current.Version, domain.RevisionRevert); err != nil {
+		return domain.Medicine{}, err
+	}
+
+	reverted := current.Apply(upd)
+	reverted.Version++
+
+	return reverted, nil
+}
+
+// newRevision builds the history entry for a change by the acting user. The
+// repository stores it in the transaction of the change.
+func newRevision(
+	ctx context.Context,
+	action domain.RevisionAction,
+	snapshot domain.Medicine,
+	changes []domain.FieldChange,
+) domain.MedicineRevision {
+	if changes == nil {
+		changes = []domain.FieldChange{}
+	}
+
+	userID, _ := domain.UserIDFromContext(ctx)
+
+	return domain.MedicineRevision{
+		MedicineID: int64(snapshot.ID),
+		Action:     action,
+		Changes:    changes,
+		Snapshot:   snapshot,
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+	}
+}
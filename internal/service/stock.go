@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/krez3f4l/audit_logger/pkg/domain/audit"
+
+	"hippo/internal/domain"
+	"hippo/internal/platform/logger"
+	"hippo/internal/repository"
+)
+
+type StockRepository interface {
+	Record(ctx context.Context, medicineID int64, movements []domain.StockMovement) ([]domain.StockMovement, error)
+	Levels(ctx context.Context, medicineID int64) ([]domain.StockItem, error)
+	Movements(ctx context.Context, medicineID int64) ([]domain.StockMovement, error)
+	LotBalances(ctx context.Context, medicineID int64, location string, now time.Time) ([]domain.LotBalance, error)
+}
+
+type Stock struct {
+	repo        StockRepository
+	medicines   MedicationDataRepository
+	lots        LotRepository
+	auditClient AuditClient
+	log         logger.Logger
+}
+
+func NewStock(
+	repo StockRepository,
+	medicines MedicationDataRepository,
+	lots LotRepository,
+	auditClient AuditClient,
+	log logger.Logger,
+) *Stock {
+	return &Stock{
+		repo:        repo,
+		medicines:   medicines,
+		lots:        lots,
+		auditClient: auditClient,
+		log:         log,
+	}
+}
+
+func (s *Stock) Level(ctx context.Context, medicineID int64) (domain.StockLevel, error) {
+	if err := s.ensureMedicine(ctx, medicineID); err != nil {
+		return domain.StockLevel{}, err
+	}
+
+	items, err := s.repo.Levels(ctx, medicineID)
+	if err != nil {
+		return domain.StockLevel{}, err
+	}
+
+	level := domain.StockLevel{MedicineID: medicineID, Locations: items}
+	for _, item := range items {
+		level.OnHand += item.OnHand
+	}
+
+	go s.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, medicineID)
+
+	return level, nil
+}
+
+func (s *Stock) Movements(ctx context.Context, medicineID int64) ([]domain.StockMovement, error) {
+	if err := s.ensureMedicine(ctx, medicineID); err != nil {
+		return nil, err
+	}
+
+	movements, err := s.repo.Movements(ctx, medicineID)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_GET, medicineID)
+
+	return movements, nil
+}
+
+func (s *Stock) Receive(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error) {
+	if req.Quantity <= 0 {
+		return nil, NewValidationError("quantity", "must be positive")
+	}
+	if err := s.checkLot(ctx, medicineID, req.LotID, true); err != nil {
+		return nil, err
+	}
+
+	return s.record(ctx, medicineID, s.movement(ctx, domain.StockReceive, req.Location, req.Quantity, req))
+}
+
+// Dispense takes stock out and is refused when the location would go negative.
+// Without a lot it draws on active lots first-expired-first-out and falls back
+// to stock received without a lot; expired and quarantined lots are skipped.
+func (s *Stock) Dispense(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error) {
+	if req.Quantity <= 0 {
+		return nil, NewValidationError("quantity", "must be positive")
+	}
+
+	if req.LotID != nil {
+		if err := s.checkLot(ctx, medicineID, req.LotID, true); err != nil {
+			return nil, err
+		}
+		return s.record(ctx, medicineID, s.movement(ctx, domain.StockDispense, req.Location, -req.Quantity, req))
+	}
+
+	balances, err := s.repo.LotBalances(ctx, medicineID, stockLocation(req.Location), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		movements []domain.StockMovement
+		remaining = req.Quantity
+	)
+	for _, b := range balances {
+		if remaining == 0 {
+			break
+		}
+		take := min(b.OnHand, remaining)
+		mv := s.movement(ctx, domain.StockDispense, req.Location, -take, req)
+		mv.LotID = &b.LotID
+		movements = append(movements, mv)
+		remaining -= take
+	}
+	if remaining > 0 {
+		movements = append(movements, s.movement(ctx, domain.StockDispense, req.Location, -remaining, req))
+	}
+
+	recorded, err := s.record(ctx, medicineID, movements...)
+
+	// Report the shortfall against everything dispensable, not just the
+	// unlotted remainder that tripped the check.
+	var insufficient *ErrInsufficientStock
+	if errors.As(err, &insufficient) {
+		insufficient.OnHand += req.Quantity - remaining
+		insufficient.Requested = req.Quantity
+	}
+
+	return recorded, err
+}
+
+// Adjust corrects stock after a count. The sign of the quantity is the direction.
+func (s *Stock) Adjust(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error) {
+	if req.Quantity == 0 {
+		return nil, NewValidationError("quantity", "cannot be zero")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, NewValidationError("reason", "is required for adjustments")
+	}
+	if err := s.checkLot(ctx, medicineID, req.LotID, false); err != nil {
+		return nil, err
+	}
+
+	return s.record(ctx, medicineID, s.movement(ctx, domain.StockAdjust, req.Location, req.Quantity, req))
+}
+
+// Transfer moves stock between two locations as a pair of ledger entries.
+// Expired and quarantined lots stay where they are.
+func (s *Stock) Transfer(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error) {
+	if req.Quantity <= 0 {
+		return nil, NewValidationError("quantity", "must be positive")
+	}
+
+	from := stockLocation(req.Location)
+	to := strings.TrimSpace(req.ToLocation)
+	if to == "" {
+		return nil, NewValidationError("to_location", "cannot be empty")
+	}
+	if to == from {
+		return nil, NewValidationError("to_location", "must differ from location")
+	}
+
+	if err := s.checkLot(ctx, medicineID, req.LotID, true); err != nil {
+		return nil, err
+	}
+
+	if req.Reference == "" {
+		req.Reference = fmt.Sprintf("transfer-%d-%d", medicineID, time.Now().UnixNano())
+	}
+
+	return s.record(ctx, medicineID,
+		s.movement(ctx, domain.StockTransfer, from, -req.Quantity, req),
+		s.movement(ctx, domain.StockTransfer, to, req.Quantity, req),
+	)
+}
+
+func (s *Stock) movement(
+	ctx context.Context,
+	typ domain.StockMovementType,
+	location string,
+	quantity int,
+	req domain.StockRequest,
+) domain.StockMovement {
+	userID, _ := domain.UserIDFromContext(ctx)
+
+	return domain.StockMovement{
+		Location:  stockLocation(location),
+		LotID:     req.LotID,
+		Type:      typ,
+		Quantity:  quantity,
+		Reason:    strings.TrimSpace(req.Reason),
+		Reference: strings.TrimSpace(req.Reference),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (s *Stock) record(ctx context.Context, medicineID int64, movements ...domain.StockMovement) ([]domain.StockMovement, error) {
+	recorded, err := s.repo.Record(ctx, medicineID, movements)
+	if err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return nil, NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+
+		var insufficient *repository.ErrInsufficientStock
+		if errors.As(err, &insufficient) {
+			return nil, NewErrInsufficientStock(insufficient.MedicineID, insufficient.Location,
+				insufficient.OnHand, insufficient.Requested, err)
+		}
+
+		return nil, err
+	}
+
+	go s.runAuditCall(ctx, audit.ENTITY_MEDICAMENT, audit.ACTION_UPDATE, medicineID)
+
+	return recorded, nil
+}
+
+// checkLot makes sure an explicitly chosen lot belongs to the medicine. When
+// usable is set the lot must also be active and unexpired; adjustments may
+// still move expired or quarantined stock, e.g. to write it off.
+func (s *Stock) checkLot(ctx context.Context, medicineID int64, lotID *int64, usable bool) error {
+	if lotID == nil {
+		return nil
+	}
+
+	lot, err := s.lots.GetByID(ctx, *lotID)
+	if err != nil {
+		return lotError(err)
+	}
+
+	switch {
+	case lot.MedicineID != medicineID:
+		return NewValidationError("lot_id", "does not belong to this medicine")
+	case !usable:
+		return nil
+	case lot.Status == domain.LotQuarantined:
+		return NewValidationError("lot_id", "lot is quarantined")
+	case lot.Expired(time.Now()):
+		return NewValidationError("lot_id", "lot is expired")
+	}
+
+	return nil
+}
+
+func (s *Stock) ensureMedicine(ctx context.Context, medicineID int64) error {
+	if _, err := s.medicines.GetByID(ctx, medicineID, false); err != nil {
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func stockLocation(location string) string {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return domain.DefaultStockLocation
+	}
+	return location
+}
+
+func (s *Stock) runAuditCall(ctx context.Context, entity, action string, id int64) {
+	logErr := s.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    entity,
+		Action:    action,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+	if logErr != nil {
+		s.log.Warn("audit log failed", logger.Err(logErr))
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/krez3f4l/audit_logger/pkg/domain/audit"
+
+	"hippo/internal/domain"
+	"hippo/internal/platform/logger"
+	"hippo/internal/repository"
+	"hippo/internal/repository/memory"
+)
+
+const stockMedicineID = 1
+
+type stubLots struct {
+	LotRepository
+	lots map[int64]domain.Lot
+}
+
+func (l *stubLots) GetByID(ctx context.Context, id int64) (domain.Lot, error) {
+	lot, ok := l.lots[id]
+	if !ok {
+		return domain.Lot{}, repository.NewNotFoundError("stubLots.GetByID", "lot", id)
+	}
+	return lot, nil
+}
+
+type nopAudit struct{}
+
+func (nopAudit) SendLogRequest(ctx context.Context, req audit.LogItem) error { return nil }
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...logger.Field)             {}
+func (nopLogger) Info(string, ...logger.Field)              {}
+func (nopLogger) Warn(string, ...logger.Field)              {}
+func (nopLogger) Error(string, ...logger.Field)             {}
+func (nopLogger) Fatal(string, ...logger.Field)             {}
+func (l nopLogger) WithFields(map[string]any) logger.Logger { return l }
+
+// newStockFixture receives 10 units of lot 1 at "pharmacy" and then lets
+// the lot expire, which is how expired stock ends up on the shelf.
+func newStockFixture(t *testing.T) (*Stock, *stubLots) {
+	t.Helper()
+
+	tomorrow := domain.NewDate(time.Now().AddDate(0, 0, 1))
+	lot := domain.Lot{ID: 1, MedicineID: stockMedicineID, LotNumber: "A1", ExpiresAt: tomorrow, Status: domain.LotActive}
+
+	lots := &stubLots{lots: map[int64]domain.Lot{lot.ID: lot}}
+	ledger := memory.NewStock(lot)
+	stock := NewStock(ledger, nil, lots, nopAudit{}, nopLogger{})
+
+	lotID := lot.ID
+	_, err := stock.Receive(context.Background(), stockMedicineID, domain.StockRequest{
+		Location: "pharmacy",
+		LotID:    &lotID,
+		Quantity: 10,
+	})
+	if err != nil {
+		t.Fatalf("Receive: %v", err)
+	}
+
+	lot.ExpiresAt = domain.NewDate(time.Now().AddDate(0, 0, -1))
+	lots.lots[lot.ID] = lot
+	ledger.SetLot(lot)
+
+	return stock, lots
+}
+
+func TestTransferRefusesUnusableLots(t *testing.T) {
+	stock, lots := newStockFixture(t)
+	ctx := context.Background()
+	lotID := int64(1)
+
+	_, err := stock.Transfer(ctx, stockMedicineID, domain.StockRequest{
+		Location: "pharmacy", ToLocation: "ward", LotID: &lotID, Quantity: 5,
+	})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Transfer of expired lot error = %v, want ValidationError", err)
+	}
+
+	lot := lots.lots[lotID]
+	lot.ExpiresAt = domain.NewDate(time.Now().AddDate(1, 0, 0))
+	lot.Status = domain.LotQuarantined
+	lots.lots[lotID] = lot
+
+	_, err = stock.Transfer(ctx, stockMedicineID, domain.StockRequest{
+		Location: "pharmacy", ToLocation: "ward", LotID: &lotID, Quantity: 5,
+	})
+	if !errors.As(err, &ve) {
+		t.Fatalf("Transfer of quarantined lot error = %v, want ValidationError", err)
+	}
+}
+
+func TestUnlottedTransferCannotMoveLotStock(t *testing.T) {
+	stock, _ := newStockFixture(t)
+	ctx := context.Background()
+
+	_, err := stock.Transfer(ctx, stockMedicineID, domain.StockRequest{
+		Location: "pharmacy", ToLocation: "ward", Quantity: 5,
+	})
+	var insufficient *ErrInsufficientStock
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("unlotted Transfer error = %v, want ErrInsufficientStock", err)
+	}
+
+	_, err = stock.Dispense(ctx, stockMedicineID, domain.StockRequest{Location: "ward", Quantity: 5})
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("Dispense at ward error = %v, want ErrInsufficientStock", err)
+	}
+	_, err = stock.Dispense(ctx, stockMedicineID, domain.StockRequest{Location: "pharmacy", Quantity: 5})
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("Dispense at pharmacy error = %v, want ErrInsufficientStock", err)
+	}
+}
+
+func TestAdjustWritesOffExpiredLot(t *testing.T) {
+	stock, _ := newStockFixture(t)
+	lotID := int64(1)
+
+	_, err := stock.Adjust(context.Background(), stockMedicineID, domain.StockRequest{
+		Location: "pharmacy", LotID: &lotID, Quantity: -10, Reason: "expired",
+	})
+	if err != nil {
+		t.Fatalf("Adjust of expired lot: %v", err)
+	}
+
+	_, err = stock.Adjust(context.Background(), stockMedicineID, domain.StockRequest{
+		Location: "pharmacy", Quantity: -1, Reason: "count",
+	})
+	var insufficient *ErrInsufficientStock
+	if !errors.As(err, &insufficient) {
+		t.Fatalf("unlotted Adjust error = %v, want ErrInsufficientStock", err)
+	}
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strconv"
@@ -9,7 +12,6 @@ import (
 
 	"github.com/golang-jwt/jwt"
 	"github.com/krez3f4l/audit_logger/pkg/domain/audit"
-	"golang.org/x/exp/rand"
 
 	"hippo/internal/domain"
 	"hippo/internal/platform/logger"
@@ -24,11 +26,21 @@ type PasswordHasher interface {
 type UsersRepository interface {
 	Create(ctx context.Context, user domain.User) error
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
+	GetRoles(ctx context.Context, userID int64) ([]domain.Role, error)
+	GrantRole(ctx context.Context, userID int64, role domain.Role) error
+	RevokeRole(ctx context.Context, userID int64, role domain.Role) error
 }
 
 type SessionRepository interface {
 	Create(ctx context.Context, token domain.RefreshSession) error
-	Get(ctx context.Context, token string) (domain.RefreshSession, error)
+	Get(ctx context.Context, tokenHash string) (domain.RefreshSession, error)
+	GetByRotated(ctx context.Context, tokenHash string) (domain.RefreshSession, time.Time, error)
+	Rotate(ctx context.Context, oldTokenHash string, session domain.RefreshSession) error
+	PruneRotated(ctx context.Context, before time.Time) (int64, error)
+	ListByUser(ctx context.Context, userID int64) ([]domain.RefreshSession, error)
+	Delete(ctx context.Context, tokenHash string) error
+	DeleteByID(ctx context.Context, userID, id int64) error
+	DeleteByUser(ctx context.Context, userID int64) (int64, error)
 }
 
 type Users struct {
@@ -78,6 +90,7 @@ func (s *Users) SignUp(ctx context.Context, sInfo domain.SignUpInfo) (int64, err
 		Email:     sInfo.Email,
 		Password:  password,
 		CreatedAt: time.Now(),
+		Roles:     []domain.Role{domain.DefaultRole},
 	}
 
 	if err = s.repo.Create(ctx, user); err != nil {
@@ -98,7 +111,7 @@ func (s *Users) SignUp(ctx context.Context, sInfo domain.SignUpInfo) (int64, err
 	return user.ID, nil
 }
 
-func (s *Users) SignIn(ctx context.Context, sInfo domain.SignInInfo) (string, string, error) {
+func (s *Users) SignIn(ctx context.Context, sInfo domain.SignInInfo, client domain.SessionClient) (string, string, error) {
 	user, err := s.repo.GetByEmail(ctx, sInfo.Email)
 	if err != nil {
 		var invalidCred *repository.ErrInvalidCredential
@@ -112,7 +125,7 @@ func (s *Users) SignIn(ctx context.Context, sInfo domain.SignInInfo) (string, st
 		return "", "", NewErrInvalidCredential(err)
 	}
 
-	accessToken, refreshToken, err := s.generateTokens(ctx, user.ID)
+	accessToken, refreshToken, err := s.generateTokens(ctx, user.ID, client)
 	if err != nil {
 		return "", "", err
 	}
@@ -122,8 +135,16 @@ func (s *Users) SignIn(ctx context.Context, sInfo domain.SignInInfo) (string, st
 	return accessToken, refreshToken, nil
 }
 
-func (s *Users) ParseToken(ctx context.Context, token string) (int64, error) {
-	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+// accessClaims are the claims of an access token. Roles are copied in when
+// the token is issued, so role changes apply from the next refresh.
+type accessClaims struct {
+	jwt.StandardClaims
+	Roles []domain.Role `json:"roles"`
+}
+
+func (s *Users) ParseToken(ctx context.Context, token string) (domain.Principal, error) {
+	var claims accessClaims
+	t, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -131,39 +152,91 @@ func (s *Users) ParseToken(ctx context.Context, token string) (int64, error) {
 	})
 
 	if err != nil {
-		return 0, err
+		return domain.Principal{}, err
 	}
 
 	if !t.Valid {
-		return 0, errors.New("invalid token")
+		return domain.Principal{}, errors.New("invalid token")
 	}
 
-	claims, ok := t.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, errors.New("invalid claims")
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return domain.Principal{}, errors.New("invalid subject")
 	}
 
-	subject, ok := claims["sub"].(string)
-	if !ok {
-		return 0, errors.New("invalid subject")
+	return domain.Principal{UserID: int64(id), Roles: claims.Roles}, nil
+}
+
+// Roles returns the roles a user holds.
+func (s *Users) Roles(ctx context.Context, userID int64) ([]domain.Role, error) {
+	roles, err := s.repo.GetRoles(ctx, userID)
+	if err != nil {
+		return nil, err
 	}
+	return roles, nil
+}
 
-	id, err := strconv.Atoi(subject)
+// GrantRole gives a user a role and returns the roles the user holds now.
+func (s *Users) GrantRole(ctx context.Context, userID int64, role string) ([]domain.Role, error) {
+	r, err := domain.ParseRole(role)
 	if err != nil {
-		return 0, errors.New("invalid subject")
+		return nil, NewValidationError("role", err.Error())
+	}
+
+	if err = s.repo.GrantRole(ctx, userID, r); err != nil {
+		return nil, userError(err)
 	}
 
-	return int64(id), nil
+	s.log.Info("role granted", logger.Int64("user_id", userID), logger.String("role", role))
+	go s.runAuditCall(ctx, audit.ENTITY_USER, audit.ACTION_UPDATE, userID)
+
+	return s.Roles(ctx, userID)
 }
 
-func (s *Users) generateTokens(ctx context.Context, userId int64) (string, string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
-		Subject:   strconv.Itoa(int(userId)),
-		IssuedAt:  time.Now().Unix(),
-		ExpiresAt: time.Now().Add(s.accessTokenLife).Unix(),
-	})
+// RevokeRole takes a role away from a user and returns the roles left.
+// Admins cannot revoke their own admin role, so the last admin cannot lock
+// everyone out by accident.
+func (s *Users) RevokeRole(ctx context.Context, userID int64, role string) ([]domain.Role, error) {
+	r, err := domain.ParseRole(role)
+	if err != nil {
+		return nil, NewValidationError("role", err.Error())
+	}
+
+	if actor, ok := domain.UserIDFromContext(ctx); ok && actor == userID && r == domain.RoleAdmin {
+		return nil, NewValidationError("role", "cannot revoke your own admin role")
+	}
+
+	if err = s.repo.RevokeRole(ctx, userID, r); err != nil {
+		return nil, userError(err)
+	}
+
+	s.log.Info("role revoked", logger.Int64("user_id", userID), logger.String("role", role))
+	go s.runAuditCall(ctx, audit.ENTITY_USER, audit.ACTION_UPDATE, userID)
+
+	return s.Roles(ctx, userID)
+}
+
+// EnsureAdmins grants the admin role to the given users, skipping ids that
+// do not exist. It bootstraps access to the admin endpoints.
+func (s *Users) EnsureAdmins(ctx context.Context, userIDs []int64) error {
+	for _, id := range userIDs {
+		err := s.repo.GrantRole(ctx, id, domain.RoleAdmin)
+
+		var repoNotFound *repository.NotFoundError
+		if errors.As(err, &repoNotFound) {
+			s.log.Warn("configured admin user does not exist", logger.Int64("user_id", id))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	accessToken, err := token.SignedString(s.hmacSecret)
+// generateTokens issues an access token and opens a new session for it.
+func (s *Users) generateTokens(ctx context.Context, userId int64, client domain.SessionClient) (string, string, error) {
+	accessToken, err := s.newAccessToken(ctx, userId)
 	if err != nil {
 		return "", "", err
 	}
@@ -173,10 +246,15 @@ func (s *Users) generateTokens(ctx context.Context, userId int64) (string, strin
 		return "", "", err
 	}
 
+	now := time.Now()
 	if err := s.sessionRepo.Create(ctx, domain.RefreshSession{
-		UserID:    userId,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(s.refreshTokenLife),
+		UserID:     userId,
+		TokenHash:  hashRefreshToken(refreshToken),
+		UserAgent:  client.UserAgent,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.refreshTokenLife),
 	}); err != nil {
 		return "", "", err
 	}
@@ -184,30 +262,203 @@ func (s *Users) generateTokens(ctx context.Context, userId int64) (string, strin
 	return accessToken, refreshToken, nil
 }
 
+func (s *Users) newAccessToken(ctx context.Context, userId int64) (string, error) {
+	roles, err := s.repo.GetRoles(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   strconv.Itoa(int(userId)),
+			IssuedAt:  time.Now().Unix(),
+			ExpiresAt: time.Now().Add(s.accessTokenLife).Unix(),
+		},
+		Roles: roles,
+	})
+
+	return token.SignedString(s.hmacSecret)
+}
+
+// refreshReuseGrace is how long after rotation an old refresh token from the
+// rotating client is rejected without being treated as reuse.
+const refreshReuseGrace = 10 * time.Second
+
+// auditActionTokenReuse marks a session revoked over refresh token reuse in
+// the audit log. The audit service has no action of its own for it; no other
+// user event is sent as a delete.
+const auditActionTokenReuse = audit.ACTION_DELETE
+
+// newRefreshToken returns 32 random bytes from the system CSPRNG, hex
+// encoded. Only its hash is stored.
 func newRefreshToken() (string, error) {
 	b := make([]byte, 32)
 
-	s := rand.NewSource(uint64(time.Now().Unix()))
-	r := rand.New(s)
-
-	if _, err := r.Read(b); err != nil {
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%x", b), nil
+	return hex.EncodeToString(b), nil
+}
+
+// hashRefreshToken is the form a refresh token is stored and looked up in.
+// The token carries 256 bits of entropy, so a plain SHA-256 is enough.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
-func (s *Users) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
-	session, err := s.sessionRepo.Get(ctx, refreshToken)
+// RefreshToken redeems a refresh token for a new token pair. The session
+// keeps its ID and gets a new refresh token, so a token works only once.
+// Presenting a token that was already rotated means it leaked, and the
+// whole session is revoked.
+func (s *Users) RefreshToken(ctx context.Context, refreshToken string, client domain.SessionClient) (string, string, error) {
+	tokenHash := hashRefreshToken(refreshToken)
+
+	session, err := s.sessionRepo.Get(ctx, tokenHash)
 	if err != nil {
-		return "", "", err
+		return "", "", s.checkTokenReuse(ctx, tokenHash, client, err)
 	}
 
-	if session.ExpiresAt.Unix() < time.Now().Unix() {
+	now := time.Now()
+	if session.ExpiresAt.Before(now) {
+		if err := s.sessionRepo.Delete(ctx, tokenHash); err != nil {
+			s.log.Warn("failed to delete expired session", logger.Int64("session_id", session.ID), logger.Err(err))
+		}
 		return "", "", NewErrRefreshTokenExpired()
 	}
 
-	return s.generateTokens(ctx, session.UserID)
+	accessToken, err := s.newAccessToken(ctx, session.UserID)
+	if err != nil {
+		return "", "", err
+	}
+
+	nextToken, err := newRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+	session.TokenHash = hashRefreshToken(nextToken)
+	session.UserAgent = client.UserAgent
+	session.IP = client.IP
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.refreshTokenLife)
+
+	if err := s.sessionRepo.Rotate(ctx, tokenHash, session); err != nil {
+		return "", "", s.checkTokenReuse(ctx, tokenHash, client, err)
+	}
+
+	// A token rotated out more than a token lifetime ago would have expired
+	// by now anyway, so there is no reuse left to detect with it.
+	if _, err := s.sessionRepo.PruneRotated(ctx, now.Add(-s.refreshTokenLife)); err != nil {
+		s.log.Warn("failed to prune rotated refresh tokens", logger.Err(err))
+	}
+
+	return accessToken, nextToken, nil
+}
+
+// checkTokenReuse handles a refresh token that matches no live session. If
+// the token was rotated out of a session, that session is revoked and
+// ErrRefreshTokenReused returned; otherwise err is returned unchanged.
+// The one exception is a token rotated less than refreshReuseGrace ago and
+// presented by the client that rotated it, which is a client racing itself,
+// e.g. two tabs refreshing at once, and is only rejected.
+func (s *Users) checkTokenReuse(ctx context.Context, tokenHash string, client domain.SessionClient, err error) error {
+	var notFound *repository.ErrTokenNotFound
+	if !errors.As(err, &notFound) {
+		return err
+	}
+
+	session, rotatedAt, lookupErr := s.sessionRepo.GetByRotated(ctx, tokenHash)
+	if errors.As(lookupErr, &notFound) {
+		return err
+	}
+	if lookupErr != nil {
+		return lookupErr
+	}
+
+	// the session holds the client of its latest rotation
+	sameClient := session.UserAgent == client.UserAgent && session.IP == client.IP
+	if sameClient && time.Since(rotatedAt) < refreshReuseGrace {
+		s.log.Info("rotated refresh token presented again by the rotating client within grace window",
+			logger.Int64("user_id", session.UserID),
+			logger.Int64("session_id", session.ID),
+		)
+		return err
+	}
+
+	revokeErr := s.sessionRepo.DeleteByID(ctx, session.UserID, session.ID)
+	var repoNotFound *repository.NotFoundError
+	if revokeErr != nil && !errors.As(revokeErr, &repoNotFound) {
+		return revokeErr
+	}
+
+	s.log.Warn("refresh token reuse detected, session revoked",
+		logger.Int64("user_id", session.UserID),
+		logger.Int64("session_id", session.ID),
+		logger.String("ip", session.IP),
+		logger.String("client_ip", client.IP),
+	)
+	// the event must not be lost with the request that triggered it
+	go s.runAuditCall(context.WithoutCancel(ctx), audit.ENTITY_USER, auditActionTokenReuse, session.UserID)
+
+	return NewErrRefreshTokenReused(session.UserID, session.ID)
+}
+
+// Sessions lists the active sessions of a user. The session holding
+// currentToken, if any, is flagged as current.
+func (s *Users) Sessions(ctx context.Context, userID int64, currentToken string) ([]domain.RefreshSession, error) {
+	sessions, err := s.sessionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentHash string
+	if currentToken != "" {
+		currentHash = hashRefreshToken(currentToken)
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].TokenHash == currentHash
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one session of a user.
+func (s *Users) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	if err := s.sessionRepo.DeleteByID(ctx, userID, sessionID); err != nil {
+		return userError(err)
+	}
+
+	s.log.Info("session revoked", logger.Int64("user_id", userID), logger.Int64("session_id", sessionID))
+
+	return nil
+}
+
+// Logout ends the session a refresh token belongs to. Unknown or already
+// used tokens are not an error: the session is gone either way. Access
+// tokens already issued stay valid until they expire.
+func (s *Users) Logout(ctx context.Context, refreshToken string) error {
+	err := s.sessionRepo.Delete(ctx, hashRefreshToken(refreshToken))
+
+	var notFound *repository.ErrTokenNotFound
+	if errors.As(err, &notFound) {
+		return nil
+	}
+
+	return err
+}
+
+// LogoutAll ends every session of a user and returns how many were ended.
+func (s *Users) LogoutAll(ctx context.Context, userID int64) (int64, error) {
+	n, err := s.sessionRepo.DeleteByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.Info("sessions revoked", logger.Int64("user_id", userID), logger.Int64("sessions", n))
+
+	return n, nil
 }
 
 func (s *Users) runAuditCall(ctx context.Context, entity, action string, id int64) {
@@ -221,3 +472,11 @@ func (s *Users) runAuditCall(ctx context.Context, entity, action string, id int6
 		s.log.Warn("audit log failed", logger.Err(logErr))
 	}
 }
+
+func userError(err error) error {
+	var repoNotFound *repository.NotFoundError
+	if errors.As(err, &repoNotFound) {
+		return NewNotFoundError(repoNotFound.Entity, repoNotFound.ID, err)
+	}
+	return err
+}
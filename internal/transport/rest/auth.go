@@ -11,6 +11,8 @@ import (
 	"hippo/internal/service"
 )
 
+const refreshCookieName = "refresh-token"
+
 func (h *Handler) handleSignUp(w http.ResponseWriter, r *http.Request) {
 	const op = "handleSignUp"
 	ctx := r.Context()
@@ -102,7 +104,7 @@ func (h *Handler) handleSignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.usersService.SignIn(ctx, sInfo)
+	accessToken, refreshToken, err := h.usersService.SignIn(ctx, sInfo, sessionClientFromRequest(r))
 	if err != nil {
 		var invalidCred *service.ErrInvalidCredential
 		if errors.As(err, &invalidCred) {
@@ -121,14 +123,7 @@ func (h *Handler) handleSignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh-token",
-		Value:    refreshToken,
-		HttpOnly: true,
-		Secure:   true,
-		Path:     "/",
-		SameSite: http.SameSiteStrictMode,
-	})
+	setRefreshCookie(w, refreshToken)
 
 	h.respondWithJSON(w, http.StatusOK, op, map[string]string{
 		"access_token": accessToken,
@@ -147,7 +142,7 @@ func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cookie, err := r.Cookie("refresh-token")
+	cookie, err := r.Cookie(refreshCookieName)
 	if err != nil {
 		h.logError(op, err)
 		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
@@ -157,10 +152,20 @@ func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.usersService.RefreshToken(ctx, cookie.Value)
+	accessToken, refreshToken, err := h.usersService.RefreshToken(ctx, cookie.Value, sessionClientFromRequest(r))
 	if err != nil {
 		h.logError(op, err)
 
+		var reused *service.ErrRefreshTokenReused
+		if errors.As(err, &reused) {
+			clearRefreshCookie(w)
+			h.respondWithJSON(w, http.StatusUnauthorized, op, ErrorResponse{
+				Code:    "refresh_token_reused",
+				Message: "Refresh token was already used. The session has been revoked, please log in again.",
+			})
+			return
+		}
+
 		var expired *service.ErrRefreshTokenExpired
 		if errors.As(err, &expired) {
 			h.respondWithJSON(w, http.StatusUnauthorized, op, ErrorResponse{
@@ -177,16 +182,174 @@ func (h *Handler) handleRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setRefreshCookie(w, refreshToken)
+
+	h.respondWithJSON(w, http.StatusOK, op, map[string]string{
+		"access_token": accessToken,
+	})
+}
+
+func (h *Handler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	const op = "handleLogout"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	cookie, err := r.Cookie(refreshCookieName)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "missing_cookie",
+			Message: "Refresh token cookie is missing",
+		})
+		return
+	}
+
+	if err := h.usersService.Logout(ctx, cookie.Value); err != nil {
+		h.logError(op, err)
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to log out",
+		})
+		return
+	}
+
+	clearRefreshCookie(w)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	const op = "handleLogoutAll"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	userID, _ := domain.UserIDFromContext(ctx)
+
+	revoked, err := h.usersService.LogoutAll(ctx, userID)
+	if err != nil {
+		h.logError(op, err)
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to log out",
+		})
+		return
+	}
+
+	clearRefreshCookie(w)
+	h.respondWithJSON(w, http.StatusOK, op, map[string]int64{
+		"revoked_sessions": revoked,
+	})
+}
+
+func (h *Handler) handleGetSessions(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetSessions"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	userID, _ := domain.UserIDFromContext(ctx)
+
+	var current string
+	if cookie, err := r.Cookie(refreshCookieName); err == nil {
+		current = cookie.Value
+	}
+
+	sessions, err := h.usersService.Sessions(ctx, userID, current)
+	if err != nil {
+		h.logError(op, err)
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve sessions",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, sessions)
+}
+
+func (h *Handler) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	const op = "handleDeleteSession"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid session ID",
+		})
+		return
+	}
+
+	userID, _ := domain.UserIDFromContext(ctx)
+
+	if err := h.usersService.RevokeSession(ctx, userID, id); err != nil {
+		h.logError(op, err)
+
+		var notFound *service.NotFoundError
+		if errors.As(err, &notFound) {
+			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+				Code:    "not_found",
+				Message: "Session not found",
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to revoke session",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setRefreshCookie(w http.ResponseWriter, token string) {
 	http.SetCookie(w, &http.Cookie{
-		Name:     "refresh-token",
-		Value:    refreshToken,
+		Name:     refreshCookieName,
+		Value:    token,
 		HttpOnly: true,
 		Secure:   true,
 		Path:     "/",
 		SameSite: http.SameSiteStrictMode,
 	})
+}
 
-	h.respondWithJSON(w, http.StatusOK, op, map[string]string{
-		"access_token": accessToken,
+func clearRefreshCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookieName,
+		Value:    "",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		Path:     "/",
+		SameSite: http.SameSiteStrictMode,
 	})
 }
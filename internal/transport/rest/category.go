@@ -0,0 +1,321 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+func (h *Handler) handleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	const op = "handleCreateCategory"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	var category domain.Category
+	if err := json.NewDecoder(r.Body).Decode(&category); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	category, err := h.categoriesService.Create(ctx, category)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondCategoryError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to create category",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/categories/%d", category.ID))
+	h.respondWithJSON(w, http.StatusCreated, op, category)
+}
+
+func (h *Handler) handleGetCategories(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetCategories"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	categories, err := h.categoriesService.List(ctx)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve categories",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, categories)
+}
+
+func (h *Handler) handleGetCategoryByID(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetCategoryByID"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid category ID",
+		})
+		return
+	}
+
+	category, err := h.categoriesService.GetByID(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondCategoryError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve category",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, category)
+}
+
+func (h *Handler) handleUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	const op = "handleUpdateCategory"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid category ID",
+		})
+		return
+	}
+
+	var upd domain.UpdateCategory
+	if err = json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	category, err := h.categoriesService.Update(ctx, id, upd)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondCategoryError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to update category",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, category)
+}
+
+func (h *Handler) handleDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	const op = "handleDeleteCategory"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid category ID",
+		})
+		return
+	}
+
+	if err = h.categoriesService.Delete(ctx, id); err != nil {
+		h.logError(op, err)
+
+		if h.respondCategoryError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to delete category",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleGetCategoryMedicines(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetCategoryMedicines"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid category ID",
+		})
+		return
+	}
+
+	recursive, err := getBoolFromQuery(r, "recursive")
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	opts, err := getListOptionsFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	page, err := h.categoriesService.Medicines(ctx, id, recursive, opts)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondCategoryError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve medicines",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, page)
+}
+
+// respondCategoryError writes the response for errors shared by the
+// category endpoints and reports whether it did.
+func (h *Handler) respondCategoryError(w http.ResponseWriter, op string, err error) bool {
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "validation_failed",
+			Message: "Invalid input",
+			Details: ve.Error(),
+		})
+		return true
+	}
+
+	var notFound *service.NotFoundError
+	if errors.As(err, &notFound) {
+		h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+			Code:    "not_found",
+			Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+		})
+		return true
+	}
+
+	var duplicate *service.ErrDuplicateCategory
+	if errors.As(err, &duplicate) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code:    "duplicate_category",
+			Message: fmt.Sprintf("Category %q already exists", duplicate.Code),
+			Details: map[string]int64{"existing_id": duplicate.ExistingID},
+		})
+		return true
+	}
+
+	var inUse *service.ErrCategoryInUse
+	if errors.As(err, &inUse) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code:    "category_in_use",
+			Message: "Category has subcategories or medicines and cannot be deleted",
+			Details: map[string]int{"subcategories": inUse.Children, "medicines": inUse.Medicines},
+		})
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,225 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+func (h *Handler) handleGetDosageForms(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetDosageForms"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	forms, err := h.dosageFormsService.List(ctx)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve dosage forms",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, forms)
+}
+
+func (h *Handler) handleGetDosageForm(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetDosageForm"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	form, err := h.dosageFormsService.Get(ctx, mux.Vars(r)["code"])
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondDosageFormError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve dosage form",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, form)
+}
+
+func (h *Handler) handleCreateDosageForm(w http.ResponseWriter, r *http.Request) {
+	const op = "handleCreateDosageForm"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	var form domain.DosageForm
+	if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	form, err := h.dosageFormsService.Create(ctx, form)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondDosageFormError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to create dosage form",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/dosage-forms/%s", form.Code))
+	h.respondWithJSON(w, http.StatusCreated, op, form)
+}
+
+func (h *Handler) handleUpdateDosageForm(w http.ResponseWriter, r *http.Request) {
+	const op = "handleUpdateDosageForm"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	var upd domain.UpdateDosageForm
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	form, err := h.dosageFormsService.Update(ctx, mux.Vars(r)["code"], upd)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondDosageFormError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to update dosage form",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, form)
+}
+
+func (h *Handler) handleDeleteDosageForm(w http.ResponseWriter, r *http.Request) {
+	const op = "handleDeleteDosageForm"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	if err := h.dosageFormsService.Delete(ctx, mux.Vars(r)["code"]); err != nil {
+		h.logError(op, err)
+
+		if h.respondDosageFormError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to delete dosage form",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondDosageFormError writes the response for errors shared by the
+// dosage form endpoints and reports whether it did.
+func (h *Handler) respondDosageFormError(w http.ResponseWriter, op string, err error) bool {
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "validation_failed",
+			Message: "Invalid input",
+			Details: ve.Error(),
+		})
+		return true
+	}
+
+	var notFound *service.NotFoundError
+	if errors.As(err, &notFound) {
+		h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+			Code:    "not_found",
+			Message: fmt.Sprintf("%s %v not found", notFound.Entity, notFound.ID),
+		})
+		return true
+	}
+
+	var duplicate *service.ErrDuplicateDosageForm
+	if errors.As(err, &duplicate) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code:    "duplicate_dosage_form",
+			Message: fmt.Sprintf("Code or synonym %q is already taken", duplicate.Term),
+		})
+		return true
+	}
+
+	var inUse *service.ErrDosageFormInUse
+	if errors.As(err, &inUse) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code:    "dosage_form_in_use",
+			Message: fmt.Sprintf("Dosage form %s is used by %d medicines", inUse.Code, inUse.Medicines),
+		})
+		return true
+	}
+
+	return false
+}
@@ -10,36 +10,135 @@ import (
 
 	"hippo/internal/domain"
 	"hippo/internal/platform/logger"
+	"hippo/internal/service"
 )
 
 type Medicine interface {
 	Create(ctx context.Context, medicament domain.Medicine) (int64, error)
-	Update(ctx context.Context, id int64, upd domain.UpdateMedicine) error
+	Update(ctx context.Context, id int64, upd domain.UpdateMedicine, ifVersion int) (domain.Medicine, error)
+	Delete(ctx context.Context, id int64, ifVersion int) error
+	GetAll(ctx context.Context, opts domain.MedicineListOptions) (domain.MedicinePage, error)
+	GetByID(ctx context.Context, id int64, includeDeleted bool) (domain.Medicine, error)
+	GetByNDC(ctx context.Context, ndc string, includeDeleted bool) (domain.Medicine, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context) (int64, error)
+	History(ctx context.Context, id int64) ([]domain.MedicineRevision, error)
+	Revert(ctx context.Context, id int64, revision int) (domain.Medicine, error)
+	Import(ctx context.Context, rows service.MedicineRowReader, dryRun bool) (domain.ImportReport, error)
+	Export(ctx context.Context, opts domain.MedicineListOptions, fn func(domain.Medicine) error) error
+	Search(ctx context.Context, query string, limit int) ([]domain.MedicineSearchResult, error)
+}
+
+type Stock interface {
+	Level(ctx context.Context, medicineID int64) (domain.StockLevel, error)
+	Movements(ctx context.Context, medicineID int64) ([]domain.StockMovement, error)
+	Receive(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error)
+	Dispense(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error)
+	Adjust(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error)
+	Transfer(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error)
+}
+
+type Lots interface {
+	Create(ctx context.Context, medicineID int64, lot domain.Lot) (domain.Lot, error)
+	GetByID(ctx context.Context, id int64) (domain.Lot, error)
+	ListByMedicine(ctx context.Context, medicineID int64) ([]domain.Lot, error)
+	Expiring(ctx context.Context, within *time.Duration) ([]domain.Lot, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateLot) (domain.Lot, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type Interactions interface {
+	Create(ctx context.Context, in domain.Interaction) (domain.Interaction, error)
+	GetByID(ctx context.Context, id int64) (domain.Interaction, error)
+	List(ctx context.Context, ingredient string) ([]domain.Interaction, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateInteraction) (domain.Interaction, error)
 	Delete(ctx context.Context, id int64) error
-	GetAll(ctx context.Context) ([]domain.Medicine, error)
-	GetByID(ctx context.Context, id int64) (domain.Medicine, error)
+	Check(ctx context.Context, medicineIDs []int64) (domain.InteractionCheckResult, error)
+	Import(ctx context.Context, rows service.InteractionRowReader, dryRun bool) (domain.InteractionImportReport, error)
+}
+
+type DosageForms interface {
+	Create(ctx context.Context, form domain.DosageForm) (domain.DosageForm, error)
+	Get(ctx context.Context, code string) (domain.DosageForm, error)
+	List(ctx context.Context) ([]domain.DosageForm, error)
+	Update(ctx context.Context, code string, upd domain.UpdateDosageForm) (domain.DosageForm, error)
+	Delete(ctx context.Context, code string) error
+}
+
+type Manufacturers interface {
+	Create(ctx context.Context, manufacturer domain.Manufacturer) (domain.Manufacturer, error)
+	GetByID(ctx context.Context, id int64) (domain.Manufacturer, error)
+	List(ctx context.Context) ([]domain.Manufacturer, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateManufacturer) (domain.Manufacturer, error)
+	Delete(ctx context.Context, id int64) error
+	Medicines(ctx context.Context, id int64, opts domain.MedicineListOptions) (domain.MedicinePage, error)
+}
+
+type Categories interface {
+	Create(ctx context.Context, category domain.Category) (domain.Category, error)
+	GetByID(ctx context.Context, id int64) (domain.Category, error)
+	List(ctx context.Context) ([]domain.Category, error)
+	Update(ctx context.Context, id int64, upd domain.UpdateCategory) (domain.Category, error)
+	Delete(ctx context.Context, id int64) error
+	Medicines(ctx context.Context, id int64, recursive bool, opts domain.MedicineListOptions) (domain.MedicinePage, error)
 }
 
 type User interface {
 	SignUp(ctx context.Context, sInfo domain.SignUpInfo) (int64, error)
-	SignIn(ctx context.Context, sInfo domain.SignInInfo) (string, string, error)
-	ParseToken(ctx context.Context, accessToken string) (int64, error)
-	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
+	SignIn(ctx context.Context, sInfo domain.SignInInfo, client domain.SessionClient) (string, string, error)
+	ParseToken(ctx context.Context, accessToken string) (domain.Principal, error)
+	Roles(ctx context.Context, userID int64) ([]domain.Role, error)
+	GrantRole(ctx context.Context, userID int64, role string) ([]domain.Role, error)
+	RevokeRole(ctx context.Context, userID int64, role string) ([]domain.Role, error)
+	RefreshToken(ctx context.Context, refreshToken string, client domain.SessionClient) (string, string, error)
+	Logout(ctx context.Context, refreshToken string) error
+	LogoutAll(ctx context.Context, userID int64) (int64, error)
+	Sessions(ctx context.Context, userID int64, currentToken string) ([]domain.RefreshSession, error)
+	RevokeSession(ctx context.Context, userID, sessionID int64) error
 }
 
+// Routes named with bulkRoutePrefix get bulkTimeout instead of timeout.
+const bulkRoutePrefix = "bulk:"
+
 type Handler struct {
-	medicinesService Medicine
-	usersService     User
-	log              logger.Logger
-	timeout          time.Duration
+	medicinesService     Medicine
+	stockService         Stock
+	lotsService          Lots
+	interactionsService  Interactions
+	dosageFormsService   DosageForms
+	manufacturersService Manufacturers
+	categoriesService    Categories
+	usersService         User
+	log                  logger.Logger
+	timeout              time.Duration
+	bulkTimeout          time.Duration
 }
 
-func NewHandler(med Medicine, usr User, log logger.Logger, timeout time.Duration) *Handler {
+func NewHandler(
+	med Medicine,
+	stock Stock,
+	lots Lots,
+	interactions Interactions,
+	dosageForms DosageForms,
+	manufacturers Manufacturers,
+	categories Categories,
+	usr User,
+	log logger.Logger,
+	timeout time.Duration,
+	bulkTimeout time.Duration,
+) *Handler {
 	return &Handler{
-		medicinesService: med,
-		usersService:     usr,
-		log:              log,
-		timeout:          timeout,
+		medicinesService:     med,
+		stockService:         stock,
+		lotsService:          lots,
+		interactionsService:  interactions,
+		dosageFormsService:   dosageForms,
+		manufacturersService: manufacturers,
+		categoriesService:    categories,
+		usersService:         usr,
+		log:                  log,
+		timeout:              timeout,
+		bulkTimeout:          bulkTimeout,
 	}
 }
 
@@ -56,19 +155,100 @@ func (h *Handler) InitRouter() *mux.Router {
 		auth.HandleFunc("/sign-up", h.handleSignUp).Methods(http.MethodPost)
 		auth.HandleFunc("/sign-in", h.handleSignIn).Methods(http.MethodGet)
 		auth.HandleFunc("/refresh", h.handleRefresh).Methods(http.MethodGet)
+		auth.HandleFunc("/logout", h.handleLogout).Methods(http.MethodPost)
+		auth.Handle("/logout-all", h.authMiddleware(http.HandlerFunc(h.handleLogoutAll))).Methods(http.MethodPost)
+		auth.Handle("/sessions", h.authMiddleware(http.HandlerFunc(h.handleGetSessions))).Methods(http.MethodGet)
+		auth.Handle("/sessions/{id}", h.authMiddleware(http.HandlerFunc(h.handleDeleteSession))).Methods(http.MethodDelete)
 	}
 
 	api := r.PathPrefix("/api/v1").Subrouter()
 	{
-		api.Use(h.authMiddleware)
+		// reading the catalog needs any role, writes are guarded per route
+		api.Use(h.authMiddleware, h.permissionMiddleware(domain.PermCatalogRead))
 
 		medicines := api.PathPrefix("/medicines").Subrouter()
 		{
-			medicines.HandleFunc("", h.handleCreateMedicine).Methods(http.MethodPost)
+			medicines.Handle("", h.permit(domain.PermCatalogWrite, h.handleCreateMedicine)).Methods(http.MethodPost)
 			medicines.HandleFunc("", h.handleGetAllMedicines).Methods(http.MethodGet)
+			medicines.HandleFunc("/search", h.handleSearchMedicines).Methods(http.MethodGet)
+			medicines.Handle("/import", h.permit(domain.PermCatalogWrite, h.handleImportMedicines)).Methods(http.MethodPost).Name(bulkRoutePrefix + "import")
+			medicines.HandleFunc("/export", h.handleExportMedicines).Methods(http.MethodGet).Name(bulkRoutePrefix + "export")
 			medicines.HandleFunc("/{id:[0-9]+}", h.handleGetMedicineByID).Methods(http.MethodGet)
-			medicines.HandleFunc("/{id:[0-9]+}", h.handleUpdateMedicine).Methods(http.MethodPut)
-			medicines.HandleFunc("/{id:[0-9]+}", h.handleDeleteMedicine).Methods(http.MethodDelete)
+			medicines.HandleFunc("/ndc/{ndc}", h.handleGetMedicineByNDC).Methods(http.MethodGet)
+			medicines.Handle("/{id:[0-9]+}", h.permit(domain.PermCatalogWrite, h.handleUpdateMedicine)).Methods(http.MethodPut)
+			medicines.Handle("/{id:[0-9]+}", h.permit(domain.PermCatalogWrite, h.handleDeleteMedicine)).Methods(http.MethodDelete)
+			medicines.Handle("/{id:[0-9]+}/restore", h.permit(domain.PermCatalogWrite, h.handleRestoreMedicine)).Methods(http.MethodPost)
+			medicines.HandleFunc("/{id:[0-9]+}/history", h.handleGetMedicineHistory).Methods(http.MethodGet)
+			medicines.Handle("/{id:[0-9]+}/history/{revision:[0-9]+}/revert", h.permit(domain.PermCatalogWrite, h.handleRevertMedicine)).Methods(http.MethodPost)
+
+			stock := medicines.PathPrefix("/{id:[0-9]+}/stock").Subrouter()
+			{
+				stock.HandleFunc("", h.handleGetStock).Methods(http.MethodGet)
+				stock.HandleFunc("/movements", h.handleGetStockMovements).Methods(http.MethodGet)
+				stock.Handle("/receive", h.permit(domain.PermStockWrite, h.handleReceiveStock)).Methods(http.MethodPost)
+				stock.Handle("/dispense", h.permit(domain.PermStockWrite, h.handleDispenseStock)).Methods(http.MethodPost)
+				stock.Handle("/adjust", h.permit(domain.PermStockWrite, h.handleAdjustStock)).Methods(http.MethodPost)
+				stock.Handle("/transfer", h.permit(domain.PermStockWrite, h.handleTransferStock)).Methods(http.MethodPost)
+			}
+
+			medicines.Handle("/{id:[0-9]+}/lots", h.permit(domain.PermCatalogWrite, h.handleCreateLot)).Methods(http.MethodPost)
+			medicines.HandleFunc("/{id:[0-9]+}/lots", h.handleGetMedicineLots).Methods(http.MethodGet)
+		}
+
+		lots := api.PathPrefix("/lots").Subrouter()
+		{
+			lots.HandleFunc("/expiring", h.handleGetExpiringLots).Methods(http.MethodGet)
+			lots.HandleFunc("/{id:[0-9]+}", h.handleGetLotByID).Methods(http.MethodGet)
+			lots.Handle("/{id:[0-9]+}", h.permit(domain.PermCatalogWrite, h.handleUpdateLot)).Methods(http.MethodPut)
+			lots.Handle("/{id:[0-9]+}", h.permit(domain.PermCatalogWrite, h.handleDeleteLot)).Methods(http.MethodDelete)
+		}
+
+		api.HandleFunc("/interactions/check", h.handleCheckInteractions).Methods(http.MethodPost)
+
+		manufacturers := api.PathPrefix("/manufacturers").Subrouter()
+		{
+			manufacturers.Handle("", h.permit(domain.PermCatalogWrite, h.handleCreateManufacturer)).Methods(http.MethodPost)
+			manufacturers.HandleFunc("", h.handleGetManufacturers).Methods(http.MethodGet)
+			manufacturers.HandleFunc("/{id:[0-9]+}", h.handleGetManufacturerByID).Methods(http.MethodGet)
+			manufacturers.Handle("/{id:[0-9]+}", h.permit(domain.PermCatalogWrite, h.handleUpdateManufacturer)).Methods(http.MethodPut)
+			manufacturers.Handle("/{id:[0-9]+}", h.permit(domain.PermCatalogWrite, h.handleDeleteManufacturer)).Methods(http.MethodDelete)
+			manufacturers.HandleFunc("/{id:[0-9]+}/medicines", h.handleGetManufacturerMedicines).Methods(http.MethodGet)
+		}
+
+		categories := api.PathPrefix("/categories").Subrouter()
+		{
+			categories.HandleFunc("", h.handleGetCategories).Methods(http.MethodGet)
+			categories.HandleFunc("/{id:[0-9]+}", h.handleGetCategoryByID).Methods(http.MethodGet)
+			categories.HandleFunc("/{id:[0-9]+}/medicines", h.handleGetCategoryMedicines).Methods(http.MethodGet)
+		}
+
+		api.HandleFunc("/dosage-forms", h.handleGetDosageForms).Methods(http.MethodGet)
+		api.HandleFunc("/dosage-forms/{code}", h.handleGetDosageForm).Methods(http.MethodGet)
+
+		admin := api.PathPrefix("/admin").Subrouter()
+		{
+			admin.Use(h.permissionMiddleware(domain.PermAdmin))
+
+			admin.HandleFunc("/medicines/purge", h.handlePurgeMedicines).Methods(http.MethodPost)
+
+			admin.HandleFunc("/interactions", h.handleCreateInteraction).Methods(http.MethodPost)
+			admin.HandleFunc("/interactions", h.handleGetInteractions).Methods(http.MethodGet)
+			admin.HandleFunc("/interactions/import", h.handleImportInteractions).Methods(http.MethodPost).Name(bulkRoutePrefix + "interactions-import")
+			admin.HandleFunc("/interactions/{id:[0-9]+}", h.handleGetInteractionByID).Methods(http.MethodGet)
+			admin.HandleFunc("/interactions/{id:[0-9]+}", h.handleUpdateInteraction).Methods(http.MethodPut)
+			admin.HandleFunc("/interactions/{id:[0-9]+}", h.handleDeleteInteraction).Methods(http.MethodDelete)
+
+			admin.HandleFunc("/dosage-forms", h.handleCreateDosageForm).Methods(http.MethodPost)
+			admin.HandleFunc("/dosage-forms/{code}", h.handleUpdateDosageForm).Methods(http.MethodPut)
+			admin.HandleFunc("/dosage-forms/{code}", h.handleDeleteDosageForm).Methods(http.MethodDelete)
+
+			admin.HandleFunc("/categories", h.handleCreateCategory).Methods(http.MethodPost)
+			admin.HandleFunc("/categories/{id:[0-9]+}", h.handleUpdateCategory).Methods(http.MethodPut)
+			admin.HandleFunc("/categories/{id:[0-9]+}", h.handleDeleteCategory).Methods(http.MethodDelete)
+
+			admin.HandleFunc("/users/{id:[0-9]+}/roles", h.handleGetUserRoles).Methods(http.MethodGet)
+			admin.HandleFunc("/users/{id:[0-9]+}/roles/{role}", h.handleGrantRole).Methods(http.MethodPut)
+			admin.HandleFunc("/users/{id:[0-9]+}/roles/{role}", h.handleRevokeRole).Methods(http.MethodDelete)
 		}
 	}
 
@@ -0,0 +1,466 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+// interactionCSVColumns are the CSV headers understood by the interaction
+// import. They match the JSON field names of domain.Interaction.
+var interactionCSVColumns = []string{"ingredient_a", "ingredient_b", "severity", "description"}
+
+func (h *Handler) handleCheckInteractions(w http.ResponseWriter, r *http.Request) {
+	const op = "handleCheckInteractions"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	var req domain.InteractionCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	result, err := h.interactionsService.Check(ctx, req.MedicineIDs)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondInteractionError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to check interactions",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, result)
+}
+
+func (h *Handler) handleCreateInteraction(w http.ResponseWriter, r *http.Request) {
+	const op = "handleCreateInteraction"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	var in domain.Interaction
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	in, err := h.interactionsService.Create(ctx, in)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondInteractionError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to create interaction",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/admin/interactions/%d", in.ID))
+	h.respondWithJSON(w, http.StatusCreated, op, in)
+}
+
+func (h *Handler) handleGetInteractions(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetInteractions"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	interactions, err := h.interactionsService.List(ctx, r.URL.Query().Get("ingredient"))
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve interactions",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, interactions)
+}
+
+func (h *Handler) handleGetInteractionByID(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetInteractionByID"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid interaction ID",
+		})
+		return
+	}
+
+	in, err := h.interactionsService.GetByID(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondInteractionError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve interaction",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, in)
+}
+
+func (h *Handler) handleUpdateInteraction(w http.ResponseWriter, r *http.Request) {
+	const op = "handleUpdateInteraction"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid interaction ID",
+		})
+		return
+	}
+
+	var upd domain.UpdateInteraction
+	if err = json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	in, err := h.interactionsService.Update(ctx, id, upd)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondInteractionError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to update interaction",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, in)
+}
+
+func (h *Handler) handleDeleteInteraction(w http.ResponseWriter, r *http.Request) {
+	const op = "handleDeleteInteraction"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid interaction ID",
+		})
+		return
+	}
+
+	if err = h.interactionsService.Delete(ctx, id); err != nil {
+		h.logError(op, err)
+
+		if h.respondInteractionError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to delete interaction",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleImportInteractions loads registry entries from CSV or NDJSON using
+// the same upload limits and dry_run flag as the medicine import.
+func (h *Handler) handleImportInteractions(w http.ResponseWriter, r *http.Request) {
+	const op = "handleImportInteractions"
+	ctx := r.Context()
+
+	dryRun, err := getBoolFromQuery(r, "dry_run")
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(h.bulkTimeout))
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+	defer body.Close()
+
+	var rows service.InteractionRowReader
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, contentTypeCSV):
+		rows, err = newCSVInteractionReader(body)
+	case strings.Contains(contentType, contentTypeNDJSON), strings.Contains(contentType, "application/ndjson"):
+		rows = newNDJSONInteractionReader(body)
+	default:
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be text/csv or application/x-ndjson",
+		})
+		return
+	}
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse import file",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	report, err := h.interactionsService.Import(ctx, rows, dryRun)
+	if err != nil {
+		h.logError(op, err)
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondWithJSON(w, http.StatusRequestEntityTooLarge, op, ErrorResponse{
+				Code:    "request_too_large",
+				Message: fmt.Sprintf("Import file must not exceed %d bytes", tooLarge.Limit),
+				Details: report,
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to import interactions",
+			Details: report,
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, report)
+}
+
+// respondInteractionError writes the response for errors shared by the
+// interaction endpoints and reports whether it did.
+func (h *Handler) respondInteractionError(w http.ResponseWriter, op string, err error) bool {
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "validation_failed",
+			Message: "Invalid input",
+			Details: ve.Error(),
+		})
+		return true
+	}
+
+	var notFound *service.NotFoundError
+	if errors.As(err, &notFound) {
+		h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+			Code:    "not_found",
+			Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+		})
+		return true
+	}
+
+	var duplicate *service.ErrDuplicateInteraction
+	if errors.As(err, &duplicate) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code: "duplicate_interaction",
+			Message: fmt.Sprintf("Interaction between %s and %s already exists",
+				duplicate.IngredientA, duplicate.IngredientB),
+		})
+		return true
+	}
+
+	return false
+}
+
+type csvInteractionReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVInteractionReader(src io.Reader) (*csvInteractionReader, error) {
+	r := csv.NewReader(src)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+
+	for _, required := range interactionCSVColumns {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain %q column", required)
+		}
+	}
+
+	return &csvInteractionReader{r: r, columns: columns}, nil
+}
+
+func (c *csvInteractionReader) Next() (domain.InteractionImportRow, error) {
+	record, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return domain.InteractionImportRow{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return domain.InteractionImportRow{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return domain.InteractionImportRow{}, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	field := func(name string) string {
+		i, ok := c.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	return domain.InteractionImportRow{
+		Line: line,
+		Interaction: domain.Interaction{
+			IngredientA: field("ingredient_a"),
+			IngredientB: field("ingredient_b"),
+			Severity:    domain.InteractionSeverity(field("severity")),
+			Description: field("description"),
+		},
+	}, nil
+}
+
+type ndjsonInteractionReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONInteractionReader(src io.Reader) *ndjsonInteractionReader {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineBytes)
+
+	return &ndjsonInteractionReader{scanner: scanner}
+}
+
+func (n *ndjsonInteractionReader) Next() (domain.InteractionImportRow, error) {
+	for n.scanner.Scan() {
+		n.line++
+
+		raw := strings.TrimSpace(n.scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		var in domain.Interaction
+		if err := json.Unmarshal([]byte(raw), &in); err != nil {
+			return domain.InteractionImportRow{Line: n.line, Err: fmt.Errorf("invalid JSON: %w", err)}, nil
+		}
+
+		return domain.InteractionImportRow{Line: n.line, Interaction: in}, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return domain.InteractionImportRow{}, err
+	}
+
+	return domain.InteractionImportRow{}, io.EOF
+}
@@ -0,0 +1,351 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+func (h *Handler) handleCreateLot(w http.ResponseWriter, r *http.Request) {
+	const op = "handleCreateLot"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	medicineID, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid medicine ID",
+		})
+		return
+	}
+
+	var lot domain.Lot
+	if err = json.NewDecoder(r.Body).Decode(&lot); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	lot, err = h.lotsService.Create(ctx, medicineID, lot)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondLotError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to create lot",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/lots/%d", lot.ID))
+	h.respondWithJSON(w, http.StatusCreated, op, lot)
+}
+
+func (h *Handler) handleGetMedicineLots(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetMedicineLots"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	medicineID, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid medicine ID",
+		})
+		return
+	}
+
+	lots, err := h.lotsService.ListByMedicine(ctx, medicineID)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondLotError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve lots",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, lots)
+}
+
+func (h *Handler) handleGetExpiringLots(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetExpiringLots"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	within, err := parseWithin(r.URL.Query().Get("within"))
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid within parameter",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	lots, err := h.lotsService.Expiring(ctx, within)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondLotError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve expiring lots",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, lots)
+}
+
+func (h *Handler) handleGetLotByID(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetLotByID"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid lot ID",
+		})
+		return
+	}
+
+	lot, err := h.lotsService.GetByID(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondLotError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve lot",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, lot)
+}
+
+func (h *Handler) handleUpdateLot(w http.ResponseWriter, r *http.Request) {
+	const op = "handleUpdateLot"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid lot ID",
+		})
+		return
+	}
+
+	var upd domain.UpdateLot
+	if err = json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	lot, err := h.lotsService.Update(ctx, id, upd)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondLotError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to update lot",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, lot)
+}
+
+func (h *Handler) handleDeleteLot(w http.ResponseWriter, r *http.Request) {
+	const op = "handleDeleteLot"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid lot ID",
+		})
+		return
+	}
+
+	if err = h.lotsService.Delete(ctx, id); err != nil {
+		h.logError(op, err)
+
+		if h.respondLotError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to delete lot",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondLotError writes the response for errors shared by the lot
+// endpoints and reports whether it did.
+func (h *Handler) respondLotError(w http.ResponseWriter, op string, err error) bool {
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "validation_failed",
+			Message: "Invalid input",
+			Details: ve.Error(),
+		})
+		return true
+	}
+
+	var notFound *service.NotFoundError
+	if errors.As(err, &notFound) {
+		h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+			Code:    "not_found",
+			Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+		})
+		return true
+	}
+
+	var duplicate *service.ErrDuplicateLot
+	if errors.As(err, &duplicate) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code:    "duplicate_lot",
+			Message: fmt.Sprintf("Lot %s already exists for this medicine", duplicate.LotNumber),
+		})
+		return true
+	}
+
+	var inUse *service.ErrLotInUse
+	if errors.As(err, &inUse) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code:    "lot_in_use",
+			Message: "Lot has stock movements and cannot be deleted",
+		})
+		return true
+	}
+
+	return false
+}
+
+// parseWithin accepts a number of days such as "30d" or a Go duration such
+// as "72h". An empty value returns nil and leaves the service default, so
+// "0d" can ask for expired lots only.
+func parseWithin(s string) (*time.Duration, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid number of days: %q", s)
+		}
+		d := time.Duration(n) * 24 * time.Hour
+		return &d, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, err
+	}
+	if d < 0 {
+		return nil, fmt.Errorf("within cannot be negative")
+	}
+	return &d, nil
+}
@@ -0,0 +1,310 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+func (h *Handler) handleCreateManufacturer(w http.ResponseWriter, r *http.Request) {
+	const op = "handleCreateManufacturer"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	var manufacturer domain.Manufacturer
+	if err := json.NewDecoder(r.Body).Decode(&manufacturer); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	manufacturer, err := h.manufacturersService.Create(ctx, manufacturer)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondManufacturerError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to create manufacturer",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/manufacturers/%d", manufacturer.ID))
+	h.respondWithJSON(w, http.StatusCreated, op, manufacturer)
+}
+
+func (h *Handler) handleGetManufacturers(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetManufacturers"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	manufacturers, err := h.manufacturersService.List(ctx)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve manufacturers",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, manufacturers)
+}
+
+func (h *Handler) handleGetManufacturerByID(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetManufacturerByID"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid manufacturer ID",
+		})
+		return
+	}
+
+	manufacturer, err := h.manufacturersService.GetByID(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondManufacturerError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve manufacturer",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, manufacturer)
+}
+
+func (h *Handler) handleUpdateManufacturer(w http.ResponseWriter, r *http.Request) {
+	const op = "handleUpdateManufacturer"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid manufacturer ID",
+		})
+		return
+	}
+
+	var upd domain.UpdateManufacturer
+	if err = json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	manufacturer, err := h.manufacturersService.Update(ctx, id, upd)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondManufacturerError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to update manufacturer",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, manufacturer)
+}
+
+func (h *Handler) handleDeleteManufacturer(w http.ResponseWriter, r *http.Request) {
+	const op = "handleDeleteManufacturer"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid manufacturer ID",
+		})
+		return
+	}
+
+	if err = h.manufacturersService.Delete(ctx, id); err != nil {
+		h.logError(op, err)
+
+		if h.respondManufacturerError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to delete manufacturer",
+		})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) handleGetManufacturerMedicines(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetManufacturerMedicines"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid manufacturer ID",
+		})
+		return
+	}
+
+	opts, err := getListOptionsFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	page, err := h.manufacturersService.Medicines(ctx, id, opts)
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondManufacturerError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve medicines",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, page)
+}
+
+// respondManufacturerError writes the response for errors shared by the
+// manufacturer endpoints and reports whether it did.
+func (h *Handler) respondManufacturerError(w http.ResponseWriter, op string, err error) bool {
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "validation_failed",
+			Message: "Invalid input",
+			Details: ve.Error(),
+		})
+		return true
+	}
+
+	var notFound *service.NotFoundError
+	if errors.As(err, &notFound) {
+		h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+			Code:    "not_found",
+			Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+		})
+		return true
+	}
+
+	var duplicate *service.ErrDuplicateManufacturer
+	if errors.As(err, &duplicate) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code:    "duplicate_manufacturer",
+			Message: fmt.Sprintf("Manufacturer %q already exists", duplicate.Name),
+			Details: map[string]int64{"existing_id": duplicate.ExistingID},
+		})
+		return true
+	}
+
+	var inUse *service.ErrManufacturerInUse
+	if errors.As(err, &inUse) {
+		h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+			Code:    "manufacturer_in_use",
+			Message: "Manufacturer is referenced by medicines and cannot be deleted",
+		})
+		return true
+	}
+
+	return false
+}
@@ -51,6 +51,10 @@ func (h *Handler) handleCreateMedicine(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if h.respondDuplicateNDC(w, op, err) {
+			return
+		}
+
 		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
 			Code:    "internal_error",
 			Message: "Failed to create medicine",
@@ -76,10 +80,32 @@ func (h *Handler) handleGetAllMedicines(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	medicines, err := h.medicinesService.GetAll(ctx)
+	opts, err := getListOptionsFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	page, err := h.medicinesService.GetAll(ctx, opts)
 	if err != nil {
 		h.logError(op, err)
 
+		var ve *service.ValidationError
+		if errors.As(err, &ve) {
+			h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+				Code:    "validation_failed",
+				Message: "Invalid input",
+				Details: ve.Error(),
+			})
+			return
+		}
+
 		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
 			Code:    "internal_error",
 			Message: "Failed to retrieve medicines",
@@ -87,7 +113,57 @@ func (h *Handler) handleGetAllMedicines(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	h.respondWithJSON(w, http.StatusOK, op, medicines)
+	h.respondWithJSON(w, http.StatusOK, op, page)
+}
+
+func (h *Handler) handleSearchMedicines(w http.ResponseWriter, r *http.Request) {
+	const op = "handleSearchMedicines"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	var limit int
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil {
+			h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+				Code:    "invalid_query",
+				Message: "Invalid query parameters",
+				Details: "limit must be an integer",
+			})
+			return
+		}
+		limit = n
+	}
+
+	results, err := h.medicinesService.Search(ctx, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		h.logError(op, err)
+
+		var ve *service.ValidationError
+		if errors.As(err, &ve) {
+			h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+				Code:    "validation_failed",
+				Message: "Invalid input",
+				Details: ve.Error(),
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to search medicines",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, results)
 }
 
 func (h *Handler) handleGetMedicineByID(w http.ResponseWriter, r *http.Request) {
@@ -113,7 +189,17 @@ func (h *Handler) handleGetMedicineByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	medicament, err := h.medicinesService.GetByID(ctx, id)
+	includeDeleted, err := getIncludeDeletedFromRequest(r)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	medicament, err := h.medicinesService.GetByID(ctx, id, includeDeleted)
 	if err != nil {
 		h.logError(op, err)
 
@@ -133,6 +219,69 @@ func (h *Handler) handleGetMedicineByID(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	etag := versionETag(medicament.Version)
+	w.Header().Set("ETag", etag)
+	if etagMatches(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, medicament)
+}
+
+func (h *Handler) handleGetMedicineByNDC(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetMedicineByNDC"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	includeDeleted, err := getIncludeDeletedFromRequest(r)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	medicament, err := h.medicinesService.GetByNDC(ctx, mux.Vars(r)["ndc"], includeDeleted)
+	if err != nil {
+		h.logError(op, err)
+
+		var ve *service.ValidationError
+		if errors.As(err, &ve) {
+			h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+				Code:    "invalid_ndc",
+				Message: "Invalid medicine NDC",
+				Details: ve.Error(),
+			})
+			return
+		}
+
+		var notFound *service.NotFoundError
+		if errors.As(err, &notFound) {
+			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+				Code:    "not_found",
+				Message: fmt.Sprintf("%s with NDC %v not found", notFound.Entity, notFound.ID),
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve medicine",
+		})
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(medicament.Version))
 	h.respondWithJSON(w, http.StatusOK, op, medicament)
 }
 
@@ -159,6 +308,16 @@ func (h *Handler) handleUpdateMedicine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ifVersion, err := getIfMatchVersion(r)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_if_match",
+			Message: "Invalid If-Match header",
+			Details: err.Error(),
+		})
+		return
+	}
+
 	var updMed domain.UpdateMedicine
 	if err = json.NewDecoder(r.Body).Decode(&updMed); err != nil {
 		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
@@ -170,10 +329,20 @@ func (h *Handler) handleUpdateMedicine(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	err = h.medicinesService.Update(ctx, id, updMed)
+	medicament, err := h.medicinesService.Update(ctx, id, updMed, ifVersion)
 	if err != nil {
 		h.logError(op, err)
 
+		var ve *service.ValidationError
+		if errors.As(err, &ve) {
+			h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+				Code:    "validation_failed",
+				Message: "Invalid input",
+				Details: ve.Error(),
+			})
+			return
+		}
+
 		var notFound *service.NotFoundError
 		if errors.As(err, &notFound) {
 			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
@@ -183,6 +352,14 @@ func (h *Handler) handleUpdateMedicine(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if h.respondVersionMismatch(w, op, err) {
+			return
+		}
+
+		if h.respondDuplicateNDC(w, op, err) {
+			return
+		}
+
 		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
 			Code:    "internal_error",
 			Message: "Failed to update medicine",
@@ -191,7 +368,8 @@ func (h *Handler) handleUpdateMedicine(w http.ResponseWriter, r *http.Request) {
 	}
 
 	w.Header().Set("Location", fmt.Sprintf("/api/v1/medicines/%d", id))
-	h.respondWithJSON(w, http.StatusOK, op, updMed)
+	w.Header().Set("ETag", versionETag(medicament.Version))
+	h.respondWithJSON(w, http.StatusOK, op, medicament)
 }
 
 func (h *Handler) handleDeleteMedicine(w http.ResponseWriter, r *http.Request) {
@@ -217,7 +395,17 @@ func (h *Handler) handleDeleteMedicine(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.medicinesService.Delete(ctx, id)
+	ifVersion, err := getIfMatchVersion(r)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_if_match",
+			Message: "Invalid If-Match header",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	err = h.medicinesService.Delete(ctx, id, ifVersion)
 	if err != nil {
 		h.logError(op, err)
 
@@ -230,6 +418,10 @@ func (h *Handler) handleDeleteMedicine(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if h.respondVersionMismatch(w, op, err) {
+			return
+		}
+
 		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
 			Code:    "internal_error",
 			Message: "Failed to update medicine",
@@ -240,6 +432,125 @@ func (h *Handler) handleDeleteMedicine(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) handleRestoreMedicine(w http.ResponseWriter, r *http.Request) {
+	const op = "handleRestoreMedicine"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid medicine ID",
+		})
+		return
+	}
+
+	err = h.medicinesService.Restore(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		var notFound *service.NotFoundError
+		if errors.As(err, &notFound) {
+			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+				Code:    "not_found",
+				Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+			})
+			return
+		}
+
+		if h.respondDuplicateNDC(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to restore medicine",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/medicines/%d", id))
+	h.respondWithJSON(w, http.StatusOK, op, map[string]string{
+		"message": "Medicine restored successfully",
+	})
+}
+
+func (h *Handler) handlePurgeMedicines(w http.ResponseWriter, r *http.Request) {
+	const op = "handlePurgeMedicines"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	purged, err := h.medicinesService.Purge(ctx)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to purge medicines",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, map[string]int64{
+		"purged": purged,
+	})
+}
+
+// respondVersionMismatch writes 412 with the current version as ETag.
+// It reports whether err was a version mismatch.
+func (h *Handler) respondVersionMismatch(w http.ResponseWriter, op string, err error) bool {
+	var mismatch *service.ErrVersionMismatch
+	if !errors.As(err, &mismatch) {
+		return false
+	}
+
+	w.Header().Set("ETag", versionETag(mismatch.Current))
+	h.respondWithJSON(w, http.StatusPreconditionFailed, op, ErrorResponse{
+		Code:    "version_mismatch",
+		Message: fmt.Sprintf("%s with ID %v has been modified", mismatch.Entity, mismatch.ID),
+		Details: map[string]int{
+			"expected_version": mismatch.Expected,
+			"current_version":  mismatch.Current,
+		},
+	})
+	return true
+}
+
+// respondDuplicateNDC writes 409 with the id of the medicine that already
+// owns the NDC. It reports whether err was a duplicate.
+func (h *Handler) respondDuplicateNDC(w http.ResponseWriter, op string, err error) bool {
+	var duplicateNDC *service.ErrDuplicateNDC
+	if !errors.As(err, &duplicateNDC) {
+		return false
+	}
+
+	h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+		Code:    "duplicate_ndc",
+		Message: fmt.Sprintf("Medicine with NDC %s already exists", duplicateNDC.NDC),
+		Details: map[string]int64{
+			"existing_id": duplicateNDC.ExistingID,
+		},
+	})
+	return true
+}
+
 func getIdFromRequest(r *http.Request) (int64, error) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
@@ -253,3 +564,69 @@ func getIdFromRequest(r *http.Request) (int64, error) {
 
 	return id, nil
 }
+
+func getListOptionsFromRequest(r *http.Request) (domain.MedicineListOptions, error) {
+	q := r.URL.Query()
+
+	opts := domain.MedicineListOptions{
+		Filter: domain.MedicineFilter{
+			NDC:              q.Get("ndc"),
+			Form:             q.Get("form"),
+			PharmaCompany:    q.Get("pharma_company"),
+			ActiveIngredient: q.Get("active_ingredient"),
+			Ingredient:       q.Get("ingredient"),
+		},
+		SortBy: domain.MedicineSortField(q.Get("sort")),
+		Order:  domain.SortOrder(q.Get("order")),
+	}
+
+	includeDeleted, err := getIncludeDeletedFromRequest(r)
+	if err != nil {
+		return opts, err
+	}
+	opts.IncludeDeleted = includeDeleted
+
+	if manufacturerID := q.Get("manufacturer_id"); manufacturerID != "" {
+		n, err := strconv.ParseInt(manufacturerID, 10, 64)
+		if err != nil || n <= 0 {
+			return opts, errors.New("manufacturer_id must be a positive integer")
+		}
+		opts.Filter.ManufacturerID = n
+	}
+
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return opts, errors.New("limit must be an integer")
+		}
+		opts.Limit = n
+	}
+
+	if cursor := q.Get("cursor"); cursor != "" {
+		c, err := domain.DecodeMedicineCursor(cursor)
+		if err != nil {
+			return opts, err
+		}
+		opts.After = &c
+	}
+
+	return opts, nil
+}
+
+func getIncludeDeletedFromRequest(r *http.Request) (bool, error) {
+	return getBoolFromQuery(r, "include_deleted")
+}
+
+func getBoolFromQuery(r *http.Request, key string) (bool, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", key)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,230 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	exportFormatXLSX   = "xlsx"
+
+	exportFlushEvery = 500
+)
+
+var exportCSVHeader = append([]string{"id"}, medicineCSVColumns...)
+
+type medicineEncoder interface {
+	Begin() error
+	Encode(med domain.Medicine) error
+	Flush() error
+}
+
+func (h *Handler) handleExportMedicines(w http.ResponseWriter, r *http.Request) {
+	const op = "handleExportMedicines"
+	ctx := r.Context()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatCSV
+	}
+
+	var (
+		enc         medicineEncoder
+		contentType string
+	)
+	switch format {
+	case exportFormatCSV:
+		enc, contentType = newCSVMedicineEncoder(w, false), contentTypeCSV+"; charset=utf-8"
+	case exportFormatXLSX:
+		enc, contentType = newCSVMedicineEncoder(w, true), contentTypeCSV+"; charset=utf-8"
+	case exportFormatNDJSON:
+		enc, contentType = newNDJSONMedicineEncoder(w), contentTypeNDJSON
+	default:
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: "format must be one of: csv, ndjson, xlsx",
+		})
+		return
+	}
+
+	opts, err := getListOptionsFromRequest(r)
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// the server write timeout is sized for small JSON answers
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(h.bulkTimeout))
+
+	// headers go out with the first row, so failures before it still get a JSON error
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+
+		ext := format
+		if format == exportFormatXLSX {
+			ext = exportFormatCSV
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(
+			`attachment; filename="medicines-%s.%s"`, time.Now().Format("20060102"), ext))
+		w.WriteHeader(http.StatusOK)
+
+		return enc.Begin()
+	}
+
+	rows := 0
+	err = h.medicinesService.Export(ctx, opts, func(med domain.Medicine) error {
+		if err := start(); err != nil {
+			return err
+		}
+		if err := enc.Encode(med); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushEvery == 0 {
+			return enc.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = start()
+	}
+	if err == nil {
+		err = enc.Flush()
+	}
+
+	if err != nil {
+		h.logError(op, err)
+
+		if started {
+			// the status line is gone already, the client sees a truncated body
+			return
+		}
+
+		var ve *service.ValidationError
+		if errors.As(err, &ve) {
+			h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+				Code:    "validation_failed",
+				Message: "Invalid input",
+				Details: ve.Error(),
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to export medicines",
+		})
+	}
+}
+
+type csvMedicineEncoder struct {
+	w     io.Writer
+	csv   *csv.Writer
+	excel bool
+	row   []string
+}
+
+// newCSVMedicineEncoder writes plain CSV, or with excel set, CSV that
+// spreadsheet applications open cleanly: a UTF-8 byte order mark, CRLF line
+// ends and cells that cannot be evaluated as formulas.
+func newCSVMedicineEncoder(w io.Writer, excel bool) *csvMedicineEncoder {
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = excel
+
+	return &csvMedicineEncoder{
+		w:     w,
+		csv:   cw,
+		excel: excel,
+		row:   make([]string, len(exportCSVHeader)),
+	}
+}
+
+func (e *csvMedicineEncoder) Begin() error {
+	if e.excel {
+		if _, err := io.WriteString(e.w, "\ufeff"); err != nil {
+			return err
+		}
+	}
+	return e.csv.Write(exportCSVHeader)
+}
+
+func (e *csvMedicineEncoder) Encode(med domain.Medicine) error {
+	e.row[0] = strconv.Itoa(med.ID)
+	e.row[1] = e.cell(med.NDC)
+	e.row[2] = e.cell(med.Name)
+	e.row[3] = e.cell(med.Dosage)
+	e.row[4] = e.cell(med.Form)
+	e.row[5] = e.cell(med.ActiveIngredient)
+	e.row[6] = e.cell(med.PharmaCompany)
+
+	return e.csv.Write(e.row)
+}
+
+func (e *csvMedicineEncoder) Flush() error {
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func (e *csvMedicineEncoder) cell(v string) string {
+	if e.excel && v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+type ndjsonMedicineEncoder struct {
+	w   io.Writer
+	enc *json.Encoder
+}
+
+func newNDJSONMedicineEncoder(w io.Writer) *ndjsonMedicineEncoder {
+	return &ndjsonMedicineEncoder{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (e *ndjsonMedicineEncoder) Begin() error {
+	return nil
+}
+
+func (e *ndjsonMedicineEncoder) Encode(med domain.Medicine) error {
+	return e.enc.Encode(med)
+}
+
+func (e *ndjsonMedicineEncoder) Flush() error {
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+const (
+	maxImportBodySize  = 64 << 20
+	maxNDJSONLineBytes = 1 << 20
+
+	contentTypeCSV    = "text/csv"
+	contentTypeNDJSON = "application/x-ndjson"
+)
+
+// medicineCSVColumns are the CSV headers understood by import and written by
+// export. They match the JSON field names of domain.Medicine.
+var medicineCSVColumns = []string{"ndc", "name", "dosage", "form", "active_ingredient", "pharma_company"}
+
+func (h *Handler) handleImportMedicines(w http.ResponseWriter, r *http.Request) {
+	const op = "handleImportMedicines"
+	ctx := r.Context()
+
+	dryRun, err := getBoolFromQuery(r, "dry_run")
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_query",
+			Message: "Invalid query parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// the server read timeout is sized for small JSON bodies
+	_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(h.bulkTimeout))
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+	defer body.Close()
+
+	var rows service.MedicineRowReader
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.Contains(contentType, contentTypeCSV):
+		rows, err = newCSVMedicineReader(body)
+	case strings.Contains(contentType, contentTypeNDJSON), strings.Contains(contentType, "application/ndjson"):
+		rows = newNDJSONMedicineReader(body)
+	default:
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be text/csv or application/x-ndjson",
+		})
+		return
+	}
+	if err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse import file",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	report, err := h.medicinesService.Import(ctx, rows, dryRun)
+	if err != nil {
+		h.logError(op, err)
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondWithJSON(w, http.StatusRequestEntityTooLarge, op, ErrorResponse{
+				Code:    "request_too_large",
+				Message: fmt.Sprintf("Import file must not exceed %d bytes", tooLarge.Limit),
+				Details: report,
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to import medicines",
+			Details: report,
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, report)
+}
+
+type csvMedicineReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVMedicineReader(src io.Reader) (*csvMedicineReader, error) {
+	r := csv.NewReader(src)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+
+	for _, required := range []string{"ndc", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain %q column", required)
+		}
+	}
+
+	return &csvMedicineReader{r: r, columns: columns}, nil
+}
+
+func (c *csvMedicineReader) Next() (domain.ImportRow, error) {
+	record, err := c.r.Read()
+	if errors.Is(err, io.EOF) {
+		return domain.ImportRow{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return domain.ImportRow{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return domain.ImportRow{}, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	field := func(name string) string {
+		i, ok := c.columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	return domain.ImportRow{
+		Line: line,
+		Medicine: domain.Medicine{
+			NDC:              field("ndc"),
+			Name:             field("name"),
+			Dosage:           field("dosage"),
+			Form:             field("form"),
+			ActiveIngredient: field("active_ingredient"),
+			PharmaCompany:    field("pharma_company"),
+		},
+	}, nil
+}
+
+type ndjsonMedicineReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newNDJSONMedicineReader(src io.Reader) *ndjsonMedicineReader {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineBytes)
+
+	return &ndjsonMedicineReader{scanner: scanner}
+}
+
+func (n *ndjsonMedicineReader) Next() (domain.ImportRow, error) {
+	for n.scanner.Scan() {
+		n.line++
+
+		raw := strings.TrimSpace(n.scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		var med domain.Medicine
+		if err := json.Unmarshal([]byte(raw), &med); err != nil {
+			return domain.ImportRow{Line: n.line, Err: fmt.Errorf("invalid JSON: %w", err)}, nil
+		}
+
+		return domain.ImportRow{Line: n.line, Medicine: med}, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return domain.ImportRow{}, err
+	}
+
+	return domain.ImportRow{}, io.EOF
+}
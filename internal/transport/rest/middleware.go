@@ -3,22 +3,28 @@ package rest
 import (
 	"context"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/gorilla/mux"
+
+	"hippo/internal/domain"
 )
 
 type CtxKey int
 
+// The user id lives under the domain key, see domain.WithUserID.
 const (
-	ctxUserIDKey CtxKey = iota
-	ctxUserTokenKey
+	ctxUserTokenKey CtxKey = iota
+	ctxPrincipalKey
 )
 
 type responseWriter struct {
 	http.ResponseWriter
 	status        int
 	headerWritten bool
-	bodyWritten   bool
+	timedOut      bool
 	mu            sync.Mutex
 }
 
@@ -40,23 +46,44 @@ func (rw *responseWriter) WriteHeader(code int) {
 	}
 }
 
+// Write passes b through until the timeout middleware has answered on the
+// handler's behalf. Several writes are allowed so responses can be streamed.
 func (rw *responseWriter) Write(b []byte) (int, error) {
 	rw.mu.Lock()
 	defer rw.mu.Unlock()
 
-	if !rw.bodyWritten {
-		rw.bodyWritten = true
-		return rw.ResponseWriter.Write(b)
-	} else {
+	if rw.timedOut {
 		return 0, http.ErrHandlerTimeout
 	}
+
+	rw.headerWritten = true
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *responseWriter) Flush() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok && !rw.timedOut {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
 }
 
 func (h *Handler) timeoutMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		const op = "timeoutMiddleware"
 
-		ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+		timeout := h.timeout
+		if route := mux.CurrentRoute(r); route != nil && strings.HasPrefix(route.GetName(), bulkRoutePrefix) {
+			timeout = h.bulkTimeout
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
 		defer cancel()
 
 		done := make(chan struct{})
@@ -80,10 +107,12 @@ func (h *Handler) timeoutMiddleware(next http.Handler) http.Handler {
 		select {
 		case <-ctx.Done():
 			ww.mu.Lock()
+			streaming := ww.headerWritten
 			if !ww.headerWritten {
 				w.WriteHeader(http.StatusGatewayTimeout)
 				_, _ = w.Write([]byte(`{"error": "request timeout"}`))
-				ww.bodyWritten = true
+				ww.headerWritten = true
+				ww.timedOut = true
 
 				h.log.WithFields(map[string]interface{}{
 					"method": r.Method,
@@ -92,6 +121,17 @@ func (h *Handler) timeoutMiddleware(next http.Handler) http.Handler {
 			}
 			ww.mu.Unlock()
 
+			// a streamed response is already under way and can only be cut
+			// short by the handler, which sees ctx cancelled; w must not be
+			// returned to the server while it may still write to it
+			if streaming {
+				select {
+				case <-done:
+				case p := <-panicChan:
+					panic(p)
+				}
+			}
+
 		case <-done:
 			return
 
@@ -141,7 +181,7 @@ func (h *Handler) authMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		userID, err := h.usersService.ParseToken(r.Context(), token)
+		principal, err := h.usersService.ParseToken(r.Context(), token)
 		if err != nil {
 			h.logError(op, err)
 			h.respondWithJSON(w, http.StatusUnauthorized, op, map[string]string{
@@ -149,10 +189,37 @@ func (h *Handler) authMiddleware(next http.Handler) http.Handler {
 			})
 			return
 		}
-		ctx := context.WithValue(r.Context(), ctxUserIDKey, userID)
+		ctx := domain.WithUserID(r.Context(), principal.UserID)
 		ctx = context.WithValue(ctx, ctxUserTokenKey, maskToken(token))
+		ctx = context.WithValue(ctx, ctxPrincipalKey, principal)
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// permissionMiddleware lets a request through when the roles in its access
+// token grant perm. It must run after authMiddleware.
+func (h *Handler) permissionMiddleware(perm domain.Permission) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const op = "permissionMiddleware"
+
+			principal, _ := r.Context().Value(ctxPrincipalKey).(domain.Principal)
+			if !principal.Can(perm) {
+				h.respondWithJSON(w, http.StatusForbidden, op, map[string]string{
+					"error":      "insufficient permissions",
+					"permission": string(perm),
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// permit guards a single route with permissionMiddleware.
+func (h *Handler) permit(perm domain.Permission, next http.HandlerFunc) http.Handler {
+	return h.permissionMiddleware(perm)(next)
+}
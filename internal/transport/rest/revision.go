@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"hippo/internal/service"
+)
+
+func (h *Handler) handleGetMedicineHistory(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetMedicineHistory"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid medicine ID",
+		})
+		return
+	}
+
+	revisions, err := h.medicinesService.History(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		var notFound *service.NotFoundError
+		if errors.As(err, &notFound) {
+			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+				Code:    "not_found",
+				Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve medicine history",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, revisions)
+}
+
+func (h *Handler) handleRevertMedicine(w http.ResponseWriter, r *http.Request) {
+	const op = "handleRevertMedicine"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid medicine ID",
+		})
+		return
+	}
+
+	revision, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil || revision <= 0 {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_revision",
+			Message: "Invalid revision number",
+		})
+		return
+	}
+
+	medicament, err := h.medicinesService.Revert(ctx, id, revision)
+	if err != nil {
+		h.logError(op, err)
+
+		var notFound *service.NotFoundError
+		if errors.As(err, &notFound) {
+			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+				Code:    "not_found",
+				Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+			})
+			return
+		}
+
+		if h.respondVersionMismatch(w, op, err) {
+			return
+		}
+
+		if h.respondDuplicateNDC(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to revert medicine",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/medicines/%d", id))
+	w.Header().Set("ETag", versionETag(medicament.Version))
+	h.respondWithJSON(w, http.StatusOK, op, medicament)
+}
@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+type userRolesResponse struct {
+	UserID int64         `json:"user_id"`
+	Roles  []domain.Role `json:"roles"`
+}
+
+func (h *Handler) handleGetUserRoles(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetUserRoles"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid user ID",
+		})
+		return
+	}
+
+	roles, err := h.usersService.Roles(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve roles",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, userRolesResponse{UserID: id, Roles: roles})
+}
+
+func (h *Handler) handleGrantRole(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGrantRole"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid user ID",
+		})
+		return
+	}
+
+	roles, err := h.usersService.GrantRole(ctx, id, mux.Vars(r)["role"])
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondRoleError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to grant role",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, userRolesResponse{UserID: id, Roles: roles})
+}
+
+func (h *Handler) handleRevokeRole(w http.ResponseWriter, r *http.Request) {
+	const op = "handleRevokeRole"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid user ID",
+		})
+		return
+	}
+
+	roles, err := h.usersService.RevokeRole(ctx, id, mux.Vars(r)["role"])
+	if err != nil {
+		h.logError(op, err)
+
+		if h.respondRoleError(w, op, err) {
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to revoke role",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, userRolesResponse{UserID: id, Roles: roles})
+}
+
+// respondRoleError writes the response for errors shared by the role
+// endpoints and reports whether it did.
+func (h *Handler) respondRoleError(w http.ResponseWriter, op string, err error) bool {
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "validation_failed",
+			Message: "Invalid input",
+			Details: ve.Error(),
+		})
+		return true
+	}
+
+	var notFound *service.NotFoundError
+	if errors.As(err, &notFound) {
+		h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+			Code:    "not_found",
+			Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+		})
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,205 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"hippo/internal/domain"
+	"hippo/internal/service"
+)
+
+type stockMovementFunc func(ctx context.Context, medicineID int64, req domain.StockRequest) ([]domain.StockMovement, error)
+
+func (h *Handler) handleGetStock(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetStock"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid medicine ID",
+		})
+		return
+	}
+
+	level, err := h.stockService.Level(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		var notFound *service.NotFoundError
+		if errors.As(err, &notFound) {
+			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+				Code:    "not_found",
+				Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve stock",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, level)
+}
+
+func (h *Handler) handleGetStockMovements(w http.ResponseWriter, r *http.Request) {
+	const op = "handleGetStockMovements"
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid medicine ID",
+		})
+		return
+	}
+
+	movements, err := h.stockService.Movements(ctx, id)
+	if err != nil {
+		h.logError(op, err)
+
+		var notFound *service.NotFoundError
+		if errors.As(err, &notFound) {
+			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+				Code:    "not_found",
+				Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to retrieve stock movements",
+		})
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, op, movements)
+}
+
+func (h *Handler) handleReceiveStock(w http.ResponseWriter, r *http.Request) {
+	h.handleStockMovement(w, r, "handleReceiveStock", h.stockService.Receive)
+}
+
+func (h *Handler) handleDispenseStock(w http.ResponseWriter, r *http.Request) {
+	h.handleStockMovement(w, r, "handleDispenseStock", h.stockService.Dispense)
+}
+
+func (h *Handler) handleAdjustStock(w http.ResponseWriter, r *http.Request) {
+	h.handleStockMovement(w, r, "handleAdjustStock", h.stockService.Adjust)
+}
+
+func (h *Handler) handleTransferStock(w http.ResponseWriter, r *http.Request) {
+	h.handleStockMovement(w, r, "handleTransferStock", h.stockService.Transfer)
+}
+
+// handleStockMovement decodes a StockRequest and applies move to the medicine
+// in the path. All four movement endpoints share it.
+func (h *Handler) handleStockMovement(w http.ResponseWriter, r *http.Request, op string, move stockMovementFunc) {
+	ctx := r.Context()
+
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		h.respondWithJSON(w, http.StatusUnsupportedMediaType, op, ErrorResponse{
+			Code:    "invalid_content_type",
+			Message: "Content-Type must be application/json",
+		})
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		h.logError(op, err)
+
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_id",
+			Message: "Invalid medicine ID",
+		})
+		return
+	}
+
+	var req domain.StockRequest
+	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+			Code:    "invalid_request_body",
+			Message: "Failed to parse request body",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer r.Body.Close()
+
+	movements, err := move(ctx, id, req)
+	if err != nil {
+		h.logError(op, err)
+
+		var ve *service.ValidationError
+		if errors.As(err, &ve) {
+			h.respondWithJSON(w, http.StatusBadRequest, op, ErrorResponse{
+				Code:    "validation_failed",
+				Message: "Invalid input",
+				Details: ve.Error(),
+			})
+			return
+		}
+
+		var notFound *service.NotFoundError
+		if errors.As(err, &notFound) {
+			h.respondWithJSON(w, http.StatusNotFound, op, ErrorResponse{
+				Code:    "not_found",
+				Message: fmt.Sprintf("%s with ID %v not found", notFound.Entity, notFound.ID),
+			})
+			return
+		}
+
+		var insufficient *service.ErrInsufficientStock
+		if errors.As(err, &insufficient) {
+			h.respondWithJSON(w, http.StatusConflict, op, ErrorResponse{
+				Code:    "insufficient_stock",
+				Message: fmt.Sprintf("Not enough stock at %s", insufficient.Location),
+				Details: map[string]int{
+					"on_hand":   insufficient.OnHand,
+					"requested": insufficient.Requested,
+				},
+			})
+			return
+		}
+
+		h.respondWithJSON(w, http.StatusInternalServerError, op, ErrorResponse{
+			Code:    "internal_error",
+			Message: "Failed to record stock movement",
+		})
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/api/v1/medicines/%d/stock", id))
+	h.respondWithJSON(w, http.StatusCreated, op, movements)
+}
@@ -2,10 +2,18 @@ package rest
 
 import (
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+
+	"hippo/internal/domain"
 )
 
+// maxUserAgentLength caps the user agent stored with a session.
+const maxUserAgentLength = 512
+
 type ErrorResponse struct {
 	Code    string      `json:"code"`
 	Message string      `json:"message"`
@@ -32,9 +40,66 @@ func getTokenFromRequest(r *http.Request) (string, error) {
 	return token, nil
 }
 
+// sessionClientFromRequest describes the device behind a request. The IP is
+// the connection's peer address; forwarding headers are not trusted.
+func sessionClientFromRequest(r *http.Request) domain.SessionClient {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return domain.SessionClient{UserAgent: userAgent, IP: ip}
+}
+
 func maskToken(token string) string {
 	if len(token) < 8 {
 		return "****"
 	}
 	return token[:4] + "****" + token[len(token)-4:]
 }
+
+func versionETag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// getIfMatchVersion reads the version a client expects from If-Match.
+// It returns 0 when the header is absent or is the "*" wildcard.
+func getIfMatchVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	if strings.Contains(header, ",") {
+		return 0, errors.New("If-Match must contain a single ETag")
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil || version <= 0 {
+		return 0, errors.New("If-Match must be an ETag returned by this API")
+	}
+
+	return version, nil
+}
+
+// etagMatches reports whether If-None-Match lists etag or the "*" wildcard.
+func etagMatches(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"hippo/internal/domain"
+	"hippo/internal/platform/logger"
+)
+
+type LotExpiry interface {
+	QuarantineExpired(ctx context.Context, now time.Time) ([]domain.Lot, error)
+	Expiring(ctx context.Context, within *time.Duration) ([]domain.Lot, error)
+}
+
+// Expiry periodically quarantines expired lots and reports the ones that
+// expire within warnWithin.
+type Expiry struct {
+	lots       LotExpiry
+	interval   time.Duration
+	warnWithin time.Duration
+	log        logger.Logger
+}
+
+func NewExpiry(lots LotExpiry, interval, warnWithin time.Duration, log logger.Logger) *Expiry {
+	return &Expiry{
+		lots:       lots,
+		interval:   interval,
+		warnWithin: warnWithin,
+		log:        log,
+	}
+}
+
+// Run scans once immediately and then every interval until ctx is done.
+func (e *Expiry) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Expiry) scan(ctx context.Context) {
+	quarantined, err := e.lots.QuarantineExpired(ctx, time.Now())
+	if err != nil {
+		e.log.Error("expiry scan: failed to quarantine expired lots", logger.Err(err))
+	}
+	for _, lot := range quarantined {
+		e.log.Info("expiry scan: lot quarantined",
+			logger.Int64("lot_id", lot.ID),
+			logger.Int64("medicine_id", lot.MedicineID),
+			logger.String("lot_number", lot.LotNumber),
+			logger.String("expires_at", lot.ExpiresAt.String()),
+		)
+	}
+
+	expiring, err := e.lots.Expiring(ctx, &e.warnWithin)
+	if err != nil {
+		e.log.Error("expiry scan: failed to list expiring lots", logger.Err(err))
+		return
+	}
+	for _, lot := range expiring {
+		e.log.Warn("expiry scan: lot expires soon",
+			logger.Int64("lot_id", lot.ID),
+			logger.Int64("medicine_id", lot.MedicineID),
+			logger.String("lot_number", lot.LotNumber),
+			logger.String("expires_at", lot.ExpiresAt.String()),
+		)
+	}
+}
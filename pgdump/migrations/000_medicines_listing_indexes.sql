@@ -0,0 +1,10 @@
+-- Indexes behind keyset pagination by name and the listing filters, which
+-- match form, company and ingredient case-insensitively.
+BEGIN;
+
+CREATE INDEX IF NOT EXISTS "medicines_name_id_idx" ON "medicines" ("name", "id");
+CREATE INDEX IF NOT EXISTS "medicines_form_idx" ON "medicines" (lower("form"));
+CREATE INDEX IF NOT EXISTS "medicines_pharma_company_idx" ON "medicines" (lower("pharma_company"));
+CREATE INDEX IF NOT EXISTS "medicines_active_ingredient_idx" ON "medicines" (lower("active_ingredient"));
+
+COMMIT;
@@ -0,0 +1,20 @@
+-- Full-text and fuzzy medicine search. search_vector ranks matches on
+-- name, ingredient and company; the trigram indexes serve typo-tolerant
+-- similarity matching on the same columns.
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE "medicines" ADD COLUMN "search_vector" tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple',
+        coalesce("name", '') || ' ' ||
+        coalesce("active_ingredient", '') || ' ' ||
+        coalesce("pharma_company", ''))
+) STORED;
+
+CREATE INDEX "medicines_search_vector_idx" ON "medicines" USING GIN ("search_vector");
+CREATE INDEX "medicines_name_trgm_idx" ON "medicines" USING GIN ("name" gin_trgm_ops);
+CREATE INDEX "medicines_active_ingredient_trgm_idx" ON "medicines" USING GIN ("active_ingredient" gin_trgm_ops);
+CREATE INDEX "medicines_pharma_company_trgm_idx" ON "medicines" USING GIN ("pharma_company" gin_trgm_ops);
+
+COMMIT;
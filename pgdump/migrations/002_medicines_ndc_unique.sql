@@ -0,0 +1,22 @@
+-- Unique NDCs for databases created before the constraint existed. It runs
+-- before 003, which turns the constraint into a partial index over live
+-- rows. Medicines have no tombstones and nothing references them yet at
+-- this point, so the oldest row per NDC is kept and later copies are moved
+-- to medicines_ndc_duplicates for review.
+BEGIN;
+
+CREATE TABLE "medicines_ndc_duplicates" AS
+SELECT m.*
+FROM "medicines" m
+WHERE EXISTS (
+    SELECT 1 FROM "medicines" older
+    WHERE older."ndc" = m."ndc" AND older."id" < m."id"
+);
+
+DELETE FROM "medicines"
+WHERE "id" IN (SELECT "id" FROM "medicines_ndc_duplicates");
+
+ALTER TABLE "medicines" DROP CONSTRAINT IF EXISTS "medicines_ndc_key";
+ALTER TABLE "medicines" ADD CONSTRAINT "medicines_ndc_key" UNIQUE ("ndc");
+
+COMMIT;
@@ -0,0 +1,11 @@
+-- Tombstones for medicines. NDC uniqueness only applies to live rows,
+-- so a deleted NDC can be reused.
+BEGIN;
+
+ALTER TABLE "medicines" ADD COLUMN "deleted_at" timestamp;
+
+ALTER TABLE "medicines" DROP CONSTRAINT "medicines_ndc_key";
+CREATE UNIQUE INDEX "medicines_ndc_key" ON "medicines" ("ndc") WHERE "deleted_at" IS NULL;
+CREATE INDEX "medicines_deleted_at_idx" ON "medicines" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+COMMIT;
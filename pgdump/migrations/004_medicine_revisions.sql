@@ -0,0 +1,15 @@
+BEGIN;
+
+CREATE TABLE "medicine_revisions" (
+    "id" SERIAL PRIMARY KEY,
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id") ON DELETE CASCADE,
+    "revision" integer NOT NULL,
+    "action" varchar NOT NULL,
+    "changes" jsonb NOT NULL,
+    "snapshot" jsonb NOT NULL,
+    "user_id" integer REFERENCES "users" ("id") ON DELETE SET NULL,
+    "created_at" timestamp NOT NULL,
+    UNIQUE ("medicine_id", "revision")
+);
+
+COMMIT;
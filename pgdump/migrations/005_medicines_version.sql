@@ -0,0 +1 @@
+ALTER TABLE "medicines" ADD COLUMN "version" integer NOT NULL DEFAULT 1;
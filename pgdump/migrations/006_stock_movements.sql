@@ -0,0 +1,28 @@
+BEGIN;
+
+CREATE TABLE "stock_movements" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id"),
+    "location" varchar NOT NULL,
+    "type" varchar NOT NULL,
+    "quantity" integer NOT NULL CHECK ("quantity" <> 0),
+    "reason" varchar NOT NULL DEFAULT '',
+    "reference" varchar NOT NULL DEFAULT '',
+    "user_id" integer REFERENCES "users" ("id") ON DELETE SET NULL,
+    "created_at" timestamp NOT NULL
+);
+
+CREATE INDEX "stock_movements_medicine_location_idx" ON "stock_movements" ("medicine_id", "location");
+
+-- the ledger is append-only
+CREATE FUNCTION "stock_movements_append_only"() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "stock_movements_no_update_delete"
+    BEFORE UPDATE OR DELETE ON "stock_movements"
+    FOR EACH ROW EXECUTE FUNCTION "stock_movements_append_only"();
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE "lots" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id") ON DELETE CASCADE,
+    "lot_number" varchar NOT NULL,
+    "expires_at" date NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'active',
+    "created_at" timestamp NOT NULL,
+    CONSTRAINT "lots_medicine_id_lot_number_key" UNIQUE ("medicine_id", "lot_number")
+);
+
+CREATE INDEX "lots_expires_at_idx" ON "lots" ("expires_at") WHERE "status" = 'active';
+
+ALTER TABLE "stock_movements" ADD COLUMN "lot_id" bigint;
+ALTER TABLE "stock_movements" ADD CONSTRAINT "stock_movements_lot_id_fkey"
+    FOREIGN KEY ("lot_id") REFERENCES "lots" ("id");
+
+CREATE INDEX "stock_movements_lot_id_idx" ON "stock_movements" ("lot_id") WHERE "lot_id" IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE "interactions" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "ingredient_a" varchar NOT NULL,
+    "ingredient_b" varchar NOT NULL,
+    "severity" varchar NOT NULL,
+    "description" text NOT NULL,
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL,
+    CONSTRAINT "interactions_ingredient_a_ingredient_b_key" UNIQUE ("ingredient_a", "ingredient_b"),
+    CHECK ("ingredient_a" < "ingredient_b")
+);
+
+CREATE INDEX "interactions_ingredient_b_idx" ON "interactions" ("ingredient_b");
+
+COMMIT;
@@ -0,0 +1,57 @@
+BEGIN;
+
+CREATE TABLE "dosage_forms" (
+    "code" varchar PRIMARY KEY,
+    "name" varchar NOT NULL,
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL
+);
+
+-- every code is also a term of its own form, so codes and synonyms are
+-- unique across the whole vocabulary
+CREATE TABLE "dosage_form_terms" (
+    "term" varchar NOT NULL,
+    "code" varchar NOT NULL REFERENCES "dosage_forms" ("code") ON DELETE CASCADE,
+    CONSTRAINT "dosage_form_terms_pkey" PRIMARY KEY ("term")
+);
+
+CREATE INDEX "dosage_form_terms_code_idx" ON "dosage_form_terms" ("code");
+
+INSERT INTO "dosage_forms" ("code", "name", "created_at", "updated_at") VALUES
+    ('tablet', 'Tablet', now(), now()),
+    ('tablet_film_coated', 'Film-coated tablet', now(), now()),
+    ('tablet_extended_release', 'Extended-release tablet', now(), now()),
+    ('capsule', 'Capsule', now(), now()),
+    ('oral_solution', 'Oral solution', now(), now()),
+    ('oral_suspension', 'Oral suspension', now(), now()),
+    ('syrup', 'Syrup', now(), now()),
+    ('injection', 'Injection', now(), now()),
+    ('cream', 'Cream', now(), now()),
+    ('ointment', 'Ointment', now(), now()),
+    ('gel', 'Gel', now(), now()),
+    ('suppository', 'Suppository', now(), now()),
+    ('inhaler', 'Inhaler', now(), now()),
+    ('drops', 'Drops', now(), now()),
+    ('patch', 'Transdermal patch', now(), now()),
+    ('powder', 'Powder', now(), now());
+
+INSERT INTO "dosage_form_terms" ("term", "code")
+SELECT "code", "code" FROM "dosage_forms";
+
+INSERT INTO "dosage_form_terms" ("term", "code") VALUES
+    ('tab', 'tablet'), ('tabs', 'tablet'), ('tablets', 'tablet'),
+    ('film-coated tablet', 'tablet_film_coated'), ('tablet, film coated', 'tablet_film_coated'),
+    ('fct', 'tablet_film_coated'),
+    ('extended-release tablet', 'tablet_extended_release'), ('tablet, extended release', 'tablet_extended_release'),
+    ('er tablet', 'tablet_extended_release'),
+    ('cap', 'capsule'), ('caps', 'capsule'), ('capsules', 'capsule'),
+    ('solution', 'oral_solution'), ('oral solution', 'oral_solution'),
+    ('suspension', 'oral_suspension'), ('oral suspension', 'oral_suspension'),
+    ('injectable', 'injection'), ('solution for injection', 'injection'), ('inj', 'injection'),
+    ('oint', 'ointment'),
+    ('supp', 'suppository'), ('suppositories', 'suppository'),
+    ('aerosol', 'inhaler'), ('inhalation aerosol', 'inhaler'),
+    ('eye drops', 'drops'), ('ear drops', 'drops'),
+    ('transdermal patch', 'patch'), ('patch, extended release', 'patch');
+
+COMMIT;
@@ -0,0 +1,39 @@
+BEGIN;
+
+CREATE TABLE "manufacturers" (
+    "id" SERIAL PRIMARY KEY,
+    "name" varchar NOT NULL,
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL
+);
+
+-- names are stored single-spaced, so lower() is the whole dedup key
+CREATE UNIQUE INDEX "manufacturers_name_key" ON "manufacturers" (lower("name"));
+
+ALTER TABLE "medicines" ADD COLUMN "manufacturer_id" integer
+    CONSTRAINT "medicines_manufacturer_id_fkey" REFERENCES "manufacturers" ("id");
+
+CREATE INDEX "medicines_manufacturer_id_idx" ON "medicines" ("manufacturer_id");
+
+-- one manufacturer per spelling that differs only in case or whitespace; the
+-- most common spelling wins, ties go to the alphabetically first
+INSERT INTO "manufacturers" ("name", "created_at", "updated_at")
+SELECT DISTINCT ON (key) name, now(), now()
+FROM (
+    SELECT
+        btrim(regexp_replace("pharma_company", '\s+', ' ', 'g')) AS name,
+        lower(btrim(regexp_replace("pharma_company", '\s+', ' ', 'g'))) AS key,
+        count(*) AS uses
+    FROM "medicines"
+    WHERE btrim(coalesce("pharma_company", '')) <> ''
+    GROUP BY 1, 2
+) spellings
+ORDER BY key, uses DESC, name;
+
+UPDATE "medicines" m
+SET "manufacturer_id" = mf."id",
+    "pharma_company" = mf."name"
+FROM "manufacturers" mf
+WHERE lower(btrim(regexp_replace(m."pharma_company", '\s+', ' ', 'g'))) = lower(mf."name");
+
+COMMIT;
@@ -0,0 +1,48 @@
+BEGIN;
+
+CREATE TABLE "ingredients" (
+    "id" SERIAL PRIMARY KEY,
+    "name" varchar NOT NULL
+);
+
+-- names are stored single-spaced, so lower() is the whole dedup key
+CREATE UNIQUE INDEX "ingredients_name_key" ON "ingredients" (lower("name"));
+
+CREATE TABLE "medicine_ingredients" (
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id") ON DELETE CASCADE,
+    "ingredient_id" integer NOT NULL REFERENCES "ingredients" ("id"),
+    "position" integer NOT NULL,
+    "strength" varchar,
+    CONSTRAINT "medicine_ingredients_pkey" PRIMARY KEY ("medicine_id", "ingredient_id")
+);
+
+CREATE INDEX "medicine_ingredients_ingredient_id_idx" ON "medicine_ingredients" ("ingredient_id");
+
+-- split combination labels such as 'amoxicillin/clavulanate' the same way
+-- the service does; a lone ingredient takes the dosage of the medicine
+CREATE TEMPORARY TABLE "ingredient_parts" ON COMMIT DROP AS
+SELECT
+    m."id" AS medicine_id,
+    btrim(regexp_replace(p.name, '\s+', ' ', 'g')) AS name,
+    p.position
+FROM "medicines" m,
+    regexp_split_to_table(m."active_ingredient", '[/+]') WITH ORDINALITY AS p(name, position)
+WHERE btrim(regexp_replace(p.name, '\s+', ' ', 'g')) <> '';
+
+INSERT INTO "ingredients" ("name")
+SELECT DISTINCT ON (lower(name)) name
+FROM "ingredient_parts"
+ORDER BY lower(name), name;
+
+INSERT INTO "medicine_ingredients" ("medicine_id", "ingredient_id", "position", "strength")
+SELECT DISTINCT ON (p.medicine_id, i."id")
+    p.medicine_id,
+    i."id",
+    p.position - 1,
+    CASE WHEN count(*) OVER (PARTITION BY p.medicine_id) = 1 THEN nullif(btrim(m."dosage"), '') END
+FROM "ingredient_parts" p
+JOIN "ingredients" i ON lower(i."name") = lower(p.name)
+JOIN "medicines" m ON m."id" = p.medicine_id
+ORDER BY p.medicine_id, i."id", p.position;
+
+COMMIT;
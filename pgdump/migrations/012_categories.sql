@@ -0,0 +1,24 @@
+BEGIN;
+
+CREATE TABLE "categories" (
+    "id" SERIAL PRIMARY KEY,
+    "code" varchar NOT NULL,
+    "name" varchar NOT NULL,
+    "parent_id" integer REFERENCES "categories" ("id"),
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL,
+    CONSTRAINT "categories_code_key" UNIQUE ("code"),
+    CONSTRAINT "categories_parent_id_check" CHECK ("parent_id" <> "id")
+);
+
+CREATE INDEX "categories_parent_id_idx" ON "categories" ("parent_id");
+
+CREATE TABLE "medicine_categories" (
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id") ON DELETE CASCADE,
+    "category_id" integer NOT NULL REFERENCES "categories" ("id"),
+    CONSTRAINT "medicine_categories_pkey" PRIMARY KEY ("medicine_id", "category_id")
+);
+
+CREATE INDEX "medicine_categories_category_id_idx" ON "medicine_categories" ("category_id");
+
+COMMIT;
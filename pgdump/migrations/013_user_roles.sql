@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE "user_roles" (
+    "user_id" integer NOT NULL,
+    "role" varchar NOT NULL,
+    CONSTRAINT "user_roles_pkey" PRIMARY KEY ("user_id", "role"),
+    CONSTRAINT "user_roles_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE,
+    CONSTRAINT "user_roles_role_check" CHECK ("role" IN ('viewer', 'pharmacist', 'admin'))
+);
+
+-- existing accounts keep read access; write access has to be granted, and
+-- app.admin_user_ids are made admins on startup
+INSERT INTO "user_roles" ("user_id", "role")
+SELECT "id", 'viewer' FROM "users";
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+-- existing sessions get no device details; their creation and last use
+-- are unknown and default to the time of the migration
+ALTER TABLE "refresh_tokens"
+    ADD COLUMN "user_agent" varchar NOT NULL DEFAULT '',
+    ADD COLUMN "ip" varchar NOT NULL DEFAULT '',
+    ADD COLUMN "created_at" timestamp NOT NULL DEFAULT now(),
+    ADD COLUMN "last_used_at" timestamp NOT NULL DEFAULT now();
+
+CREATE INDEX "refresh_tokens_token_idx" ON "refresh_tokens" ("token");
+CREATE INDEX "refresh_tokens_user_id_idx" ON "refresh_tokens" ("user_id");
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+-- tokens rotated out of a session; the session row is the family they
+-- belong to, and presenting one of them again revokes it
+CREATE TABLE "rotated_refresh_tokens" (
+    "token" varchar PRIMARY KEY,
+    "family_id" integer NOT NULL REFERENCES "refresh_tokens" ("id") ON DELETE CASCADE,
+    "rotated_at" timestamp NOT NULL
+);
+
+CREATE INDEX "rotated_refresh_tokens_family_id_idx" ON "rotated_refresh_tokens" ("family_id");
+
+COMMIT;
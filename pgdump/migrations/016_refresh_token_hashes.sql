@@ -0,0 +1,25 @@
+BEGIN;
+
+-- refresh tokens were stored in plain text; keep only their SHA-256 hex
+-- digest, which is what the service now looks tokens up by, so cookies
+-- issued before the migration keep working
+
+-- tokens from the old time-seeded generator could repeat; a duplicated
+-- token cannot be attributed to one session, so those sessions are dropped
+DELETE FROM "refresh_tokens"
+WHERE "token" IS NULL
+   OR "token" IN (
+       SELECT "token" FROM "refresh_tokens" GROUP BY "token" HAVING count(*) > 1
+   );
+
+ALTER TABLE "refresh_tokens" RENAME COLUMN "token" TO "token_hash";
+UPDATE "refresh_tokens" SET "token_hash" = encode(sha256(convert_to("token_hash", 'UTF8')), 'hex');
+ALTER TABLE "refresh_tokens" ALTER COLUMN "token_hash" SET NOT NULL;
+
+DROP INDEX "refresh_tokens_token_idx";
+CREATE UNIQUE INDEX "refresh_tokens_token_hash_key" ON "refresh_tokens" ("token_hash");
+
+ALTER TABLE "rotated_refresh_tokens" RENAME COLUMN "token" TO "token_hash";
+UPDATE "rotated_refresh_tokens" SET "token_hash" = encode(sha256(convert_to("token_hash", 'UTF8')), 'hex');
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- deleting a user sets stock_movements.user_id to NULL, which the
+-- append-only trigger used to reject along with every other update
+CREATE OR REPLACE FUNCTION "stock_movements_append_only"() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD."user_id" IS NOT NULL AND NEW."user_id" IS NULL
+        AND to_jsonb(NEW) - 'user_id' = to_jsonb(OLD) - 'user_id' THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
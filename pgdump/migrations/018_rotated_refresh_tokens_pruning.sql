@@ -0,0 +1,7 @@
+BEGIN;
+
+-- rotated tokens are pruned once they are older than the refresh token
+-- lifetime
+CREATE INDEX "rotated_refresh_tokens_rotated_at_idx" ON "rotated_refresh_tokens" ("rotated_at");
+
+COMMIT;
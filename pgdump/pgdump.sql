@@ -1,7 +1,11 @@
 CREATE TABLE "refresh_tokens" (
                                   "id" SERIAL PRIMARY KEY,
                                   "user_id" integer,
-                                  "token" varchar,
+                                  "token_hash" varchar NOT NULL,
+                                  "user_agent" varchar NOT NULL DEFAULT '',
+                                  "ip" varchar NOT NULL DEFAULT '',
+                                  "created_at" timestamp NOT NULL DEFAULT now(),
+                                  "last_used_at" timestamp NOT NULL DEFAULT now(),
                                   "expires_at" timestamp
 );
 
@@ -20,7 +24,238 @@ CREATE TABLE "medicines" (
                              "dosage" varchar,
                              "form" varchar,
                              "active_ingredient" varchar,
-                             "pharma_company" varchar
+                             "pharma_company" varchar,
+                             "deleted_at" timestamp,
+                             "version" integer NOT NULL DEFAULT 1
 );
 
 ALTER TABLE "refresh_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+CREATE UNIQUE INDEX "refresh_tokens_token_hash_key" ON "refresh_tokens" ("token_hash");
+CREATE INDEX "refresh_tokens_user_id_idx" ON "refresh_tokens" ("user_id");
+
+CREATE INDEX "medicines_name_id_idx" ON "medicines" ("name", "id");
+CREATE INDEX "medicines_form_idx" ON "medicines" (lower("form"));
+CREATE INDEX "medicines_pharma_company_idx" ON "medicines" (lower("pharma_company"));
+CREATE INDEX "medicines_active_ingredient_idx" ON "medicines" (lower("active_ingredient"));
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE "medicines" ADD COLUMN "search_vector" tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple',
+        coalesce("name", '') || ' ' ||
+        coalesce("active_ingredient", '') || ' ' ||
+        coalesce("pharma_company", ''))
+) STORED;
+
+CREATE INDEX "medicines_search_vector_idx" ON "medicines" USING GIN ("search_vector");
+CREATE INDEX "medicines_name_trgm_idx" ON "medicines" USING GIN ("name" gin_trgm_ops);
+CREATE INDEX "medicines_active_ingredient_trgm_idx" ON "medicines" USING GIN ("active_ingredient" gin_trgm_ops);
+CREATE INDEX "medicines_pharma_company_trgm_idx" ON "medicines" USING GIN ("pharma_company" gin_trgm_ops);
+
+CREATE UNIQUE INDEX "medicines_ndc_key" ON "medicines" ("ndc") WHERE "deleted_at" IS NULL;
+CREATE INDEX "medicines_deleted_at_idx" ON "medicines" ("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE TABLE "medicine_revisions" (
+                                      "id" SERIAL PRIMARY KEY,
+                                      "medicine_id" integer NOT NULL,
+                                      "revision" integer NOT NULL,
+                                      "action" varchar NOT NULL,
+                                      "changes" jsonb NOT NULL,
+                                      "snapshot" jsonb NOT NULL,
+                                      "user_id" integer,
+                                      "created_at" timestamp NOT NULL,
+                                      UNIQUE ("medicine_id", "revision")
+);
+
+ALTER TABLE "medicine_revisions" ADD FOREIGN KEY ("medicine_id") REFERENCES "medicines" ("id") ON DELETE CASCADE;
+ALTER TABLE "medicine_revisions" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE SET NULL;
+
+CREATE TABLE "stock_movements" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id"),
+    "location" varchar NOT NULL,
+    "type" varchar NOT NULL,
+    "quantity" integer NOT NULL CHECK ("quantity" <> 0),
+    "reason" varchar NOT NULL DEFAULT '',
+    "reference" varchar NOT NULL DEFAULT '',
+    "user_id" integer REFERENCES "users" ("id") ON DELETE SET NULL,
+    "created_at" timestamp NOT NULL
+);
+
+CREATE INDEX "stock_movements_medicine_location_idx" ON "stock_movements" ("medicine_id", "location");
+
+-- the ledger is append-only; the one update allowed is the ON DELETE SET NULL
+-- of user_id when the user who recorded a movement is deleted
+CREATE FUNCTION "stock_movements_append_only"() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD."user_id" IS NOT NULL AND NEW."user_id" IS NULL
+        AND to_jsonb(NEW) - 'user_id' = to_jsonb(OLD) - 'user_id' THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "stock_movements_no_update_delete"
+    BEFORE UPDATE OR DELETE ON "stock_movements"
+    FOR EACH ROW EXECUTE FUNCTION "stock_movements_append_only"();
+
+CREATE TABLE "lots" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id") ON DELETE CASCADE,
+    "lot_number" varchar NOT NULL,
+    "expires_at" date NOT NULL,
+    "status" varchar NOT NULL DEFAULT 'active',
+    "created_at" timestamp NOT NULL,
+    CONSTRAINT "lots_medicine_id_lot_number_key" UNIQUE ("medicine_id", "lot_number")
+);
+
+CREATE INDEX "lots_expires_at_idx" ON "lots" ("expires_at") WHERE "status" = 'active';
+
+ALTER TABLE "stock_movements" ADD COLUMN "lot_id" bigint;
+ALTER TABLE "stock_movements" ADD CONSTRAINT "stock_movements_lot_id_fkey"
+    FOREIGN KEY ("lot_id") REFERENCES "lots" ("id");
+
+CREATE INDEX "stock_movements_lot_id_idx" ON "stock_movements" ("lot_id") WHERE "lot_id" IS NOT NULL;
+
+CREATE TABLE "interactions" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "ingredient_a" varchar NOT NULL,
+    "ingredient_b" varchar NOT NULL,
+    "severity" varchar NOT NULL,
+    "description" text NOT NULL,
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL,
+    CONSTRAINT "interactions_ingredient_a_ingredient_b_key" UNIQUE ("ingredient_a", "ingredient_b"),
+    CHECK ("ingredient_a" < "ingredient_b")
+);
+
+CREATE INDEX "interactions_ingredient_b_idx" ON "interactions" ("ingredient_b");
+
+CREATE TABLE "dosage_forms" (
+    "code" varchar PRIMARY KEY,
+    "name" varchar NOT NULL,
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL
+);
+
+-- every code is also a term of its own form, so codes and synonyms are
+-- unique across the whole vocabulary
+CREATE TABLE "dosage_form_terms" (
+    "term" varchar NOT NULL,
+    "code" varchar NOT NULL REFERENCES "dosage_forms" ("code") ON DELETE CASCADE,
+    CONSTRAINT "dosage_form_terms_pkey" PRIMARY KEY ("term")
+);
+
+CREATE INDEX "dosage_form_terms_code_idx" ON "dosage_form_terms" ("code");
+
+INSERT INTO "dosage_forms" ("code", "name", "created_at", "updated_at") VALUES
+    ('tablet', 'Tablet', now(), now()),
+    ('tablet_film_coated', 'Film-coated tablet', now(), now()),
+    ('tablet_extended_release', 'Extended-release tablet', now(), now()),
+    ('capsule', 'Capsule', now(), now()),
+    ('oral_solution', 'Oral solution', now(), now()),
+    ('oral_suspension', 'Oral suspension', now(), now()),
+    ('syrup', 'Syrup', now(), now()),
+    ('injection', 'Injection', now(), now()),
+    ('cream', 'Cream', now(), now()),
+    ('ointment', 'Ointment', now(), now()),
+    ('gel', 'Gel', now(), now()),
+    ('suppository', 'Suppository', now(), now()),
+    ('inhaler', 'Inhaler', now(), now()),
+    ('drops', 'Drops', now(), now()),
+    ('patch', 'Transdermal patch', now(), now()),
+    ('powder', 'Powder', now(), now());
+
+INSERT INTO "dosage_form_terms" ("term", "code")
+SELECT "code", "code" FROM "dosage_forms";
+
+INSERT INTO "dosage_form_terms" ("term", "code") VALUES
+    ('tab', 'tablet'), ('tabs', 'tablet'), ('tablets', 'tablet'),
+    ('film-coated tablet', 'tablet_film_coated'), ('tablet, film coated', 'tablet_film_coated'),
+    ('fct', 'tablet_film_coated'),
+    ('extended-release tablet', 'tablet_extended_release'), ('tablet, extended release', 'tablet_extended_release'),
+    ('er tablet', 'tablet_extended_release'),
+    ('cap', 'capsule'), ('caps', 'capsule'), ('capsules', 'capsule'),
+    ('solution', 'oral_solution'), ('oral solution', 'oral_solution'),
+    ('suspension', 'oral_suspension'), ('oral suspension', 'oral_suspension'),
+    ('injectable', 'injection'), ('solution for injection', 'injection'), ('inj', 'injection'),
+    ('oint', 'ointment'),
+    ('supp', 'suppository'), ('suppositories', 'suppository'),
+    ('aerosol', 'inhaler'), ('inhalation aerosol', 'inhaler'),
+    ('eye drops', 'drops'), ('ear drops', 'drops'),
+    ('transdermal patch', 'patch'), ('patch, extended release', 'patch');
+
+CREATE TABLE "manufacturers" (
+    "id" SERIAL PRIMARY KEY,
+    "name" varchar NOT NULL,
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL
+);
+
+-- names are stored single-spaced, so lower() is the whole dedup key
+CREATE UNIQUE INDEX "manufacturers_name_key" ON "manufacturers" (lower("name"));
+
+ALTER TABLE "medicines" ADD COLUMN "manufacturer_id" integer
+    CONSTRAINT "medicines_manufacturer_id_fkey" REFERENCES "manufacturers" ("id");
+
+CREATE INDEX "medicines_manufacturer_id_idx" ON "medicines" ("manufacturer_id");
+
+CREATE TABLE "ingredients" (
+    "id" SERIAL PRIMARY KEY,
+    "name" varchar NOT NULL
+);
+
+-- names are stored single-spaced, so lower() is the whole dedup key
+CREATE UNIQUE INDEX "ingredients_name_key" ON "ingredients" (lower("name"));
+
+CREATE TABLE "medicine_ingredients" (
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id") ON DELETE CASCADE,
+    "ingredient_id" integer NOT NULL REFERENCES "ingredients" ("id"),
+    "position" integer NOT NULL,
+    "strength" varchar,
+    CONSTRAINT "medicine_ingredients_pkey" PRIMARY KEY ("medicine_id", "ingredient_id")
+);
+
+CREATE INDEX "medicine_ingredients_ingredient_id_idx" ON "medicine_ingredients" ("ingredient_id");
+
+CREATE TABLE "categories" (
+    "id" SERIAL PRIMARY KEY,
+    "code" varchar NOT NULL,
+    "name" varchar NOT NULL,
+    "parent_id" integer REFERENCES "categories" ("id"),
+    "created_at" timestamp NOT NULL,
+    "updated_at" timestamp NOT NULL,
+    CONSTRAINT "categories_code_key" UNIQUE ("code"),
+    CONSTRAINT "categories_parent_id_check" CHECK ("parent_id" <> "id")
+);
+
+CREATE INDEX "categories_parent_id_idx" ON "categories" ("parent_id");
+
+CREATE TABLE "medicine_categories" (
+    "medicine_id" integer NOT NULL REFERENCES "medicines" ("id") ON DELETE CASCADE,
+    "category_id" integer NOT NULL REFERENCES "categories" ("id"),
+    CONSTRAINT "medicine_categories_pkey" PRIMARY KEY ("medicine_id", "category_id")
+);
+
+CREATE INDEX "medicine_categories_category_id_idx" ON "medicine_categories" ("category_id");
+
+CREATE TABLE "user_roles" (
+    "user_id" integer NOT NULL,
+    "role" varchar NOT NULL,
+    CONSTRAINT "user_roles_pkey" PRIMARY KEY ("user_id", "role"),
+    CONSTRAINT "user_roles_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE,
+    CONSTRAINT "user_roles_role_check" CHECK ("role" IN ('viewer', 'pharmacist', 'admin'))
+);
+
+CREATE TABLE "rotated_refresh_tokens" (
+    "token_hash" varchar PRIMARY KEY,
+    "family_id" integer NOT NULL REFERENCES "refresh_tokens" ("id") ON DELETE CASCADE,
+    "rotated_at" timestamp NOT NULL
+);
+
+CREATE INDEX "rotated_refresh_tokens_family_id_idx" ON "rotated_refresh_tokens" ("family_id");
+CREATE INDEX "rotated_refresh_tokens_rotated_at_idx" ON "rotated_refresh_tokens" ("rotated_at");
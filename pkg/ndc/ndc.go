@@ -0,0 +1,97 @@
+// Package ndc parses National Drug Codes in the segment layouts used by the
+// FDA and normalizes them to the 11-digit 5-4-2 form used for billing.
+package ndc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalid   = errors.New("invalid NDC")
+	ErrAmbiguous = errors.New("ambiguous NDC: 10-digit codes must be hyphenated")
+)
+
+type Layout string
+
+const (
+	Layout442 Layout = "4-4-2"
+	Layout532 Layout = "5-3-2"
+	Layout541 Layout = "5-4-1"
+	Layout542 Layout = "5-4-2"
+)
+
+// NDC is a parsed code. Segments are already zero-padded to 5-4-2 widths.
+type NDC struct {
+	Labeler string
+	Product string
+	Package string
+	Layout  Layout
+}
+
+// Parse accepts the hyphenated 4-4-2, 5-3-2, 5-4-1 and 5-4-2 layouts and a
+// bare 11-digit code. A bare 10-digit code cannot be told apart between the
+// three 10-digit layouts and is rejected with ErrAmbiguous.
+func Parse(s string) (NDC, error) {
+	s = strings.TrimSpace(s)
+
+	parts := strings.Split(s, "-")
+	for _, p := range parts {
+		if p == "" || !isDigits(p) {
+			return NDC{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		switch len(s) {
+		case 11:
+			return NDC{Labeler: s[:5], Product: s[5:9], Package: s[9:], Layout: Layout542}, nil
+		case 10:
+			return NDC{}, fmt.Errorf("%w: %q", ErrAmbiguous, s)
+		}
+	case 3:
+		lab, prod, pkg := parts[0], parts[1], parts[2]
+		switch Layout(fmt.Sprintf("%d-%d-%d", len(lab), len(prod), len(pkg))) {
+		case Layout442:
+			return NDC{Labeler: "0" + lab, Product: prod, Package: pkg, Layout: Layout442}, nil
+		case Layout532:
+			return NDC{Labeler: lab, Product: "0" + prod, Package: pkg, Layout: Layout532}, nil
+		case Layout541:
+			return NDC{Labeler: lab, Product: prod, Package: "0" + pkg, Layout: Layout541}, nil
+		case Layout542:
+			return NDC{Labeler: lab, Product: prod, Package: pkg, Layout: Layout542}, nil
+		}
+	}
+
+	return NDC{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+}
+
+// Normalize parses s and returns its canonical 5-4-2 form.
+func Normalize(s string) (string, error) {
+	n, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return n.String(), nil
+}
+
+// String returns the hyphenated 11-digit 5-4-2 form, e.g. 00777-3105-02.
+func (n NDC) String() string {
+	return n.Labeler + "-" + n.Product + "-" + n.Package
+}
+
+// Digits returns the 11 digits without separators.
+func (n NDC) Digits() string {
+	return n.Labeler + n.Product + n.Package
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package ndc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		layout Layout
+	}{
+		{"0777-3105-02", "00777-3105-02", Layout442},
+		{"12345-678-90", "12345-0678-90", Layout532},
+		{"12345-6789-0", "12345-6789-00", Layout541},
+		{"12345-6789-01", "12345-6789-01", Layout542},
+		{"12345678901", "12345-6789-01", Layout542},
+		{" 0777-3105-02 ", "00777-3105-02", Layout442},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.in, got.String(), tt.want)
+		}
+		if got.Layout != tt.layout {
+			t.Errorf("Parse(%q) layout = %s, want %s", tt.in, got.Layout, tt.layout)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{"", ErrInvalid},
+		{"0777310502", ErrAmbiguous},
+		{"123456789", ErrInvalid},
+		{"123456789012", ErrInvalid},
+		{"0777-3105", ErrInvalid},
+		{"0777--02", ErrInvalid},
+		{"0777-3105-02-1", ErrInvalid},
+		{"077A-3105-02", ErrInvalid},
+		{"123-4567-89", ErrInvalid},
+		{"1234-5678-901", ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.in); !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize("0777-3105-02")
+	if err != nil {
+		t.Fatalf("Normalize error: %v", err)
+	}
+	if got != "00777-3105-02" {
+		t.Errorf("Normalize = %q, want %q", got, "00777-3105-02")
+	}
+
+	if _, err := Normalize("0777310502"); !errors.Is(err, ErrAmbiguous) {
+		t.Errorf("Normalize error = %v, want %v", err, ErrAmbiguous)
+	}
+}
+
+func TestDigits(t *testing.T) {
+	n, err := Parse("12345-678-90")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if got := n.Digits(); got != "12345067890" {
+		t.Errorf("Digits = %q, want %q", got, "12345067890")
+	}
+}
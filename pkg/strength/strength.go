@@ -0,0 +1,285 @@
+// Package strength parses medicine strengths as printed on labels, such as
+// "200mg", "0.5 mcg", "1,000 IU", "10 mEq" or "250 mg/5 mL", and converts
+// them between units of the same kind.
+package strength
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalid      = errors.New("invalid strength")
+	ErrUnknownUnit  = errors.New("unknown unit")
+	ErrIncompatible = errors.New("incompatible units")
+)
+
+type Unit string
+
+const (
+	Microgram         Unit = "mcg"
+	Milligram         Unit = "mg"
+	Gram              Unit = "g"
+	Milliliter        Unit = "mL"
+	Liter             Unit = "L"
+	InternationalUnit Unit = "IU"
+	Milliequivalent   Unit = "mEq"
+)
+
+type kind int
+
+const (
+	kindMass kind = iota + 1
+	kindVolume
+	kindActivity
+	kindCharge
+)
+
+// units maps each unit to its kind and its size in the smallest unit of
+// that kind (mcg, mL, IU or mEq).
+var units = map[Unit]struct {
+	kind   kind
+	factor float64
+}{
+	Microgram:         {kindMass, 1},
+	Milligram:         {kindMass, 1e3},
+	Gram:              {kindMass, 1e6},
+	Milliliter:        {kindVolume, 1},
+	Liter:             {kindVolume, 1e3},
+	InternationalUnit: {kindActivity, 1},
+	Milliequivalent:   {kindCharge, 1},
+}
+
+// aliases are the lowercase spellings accepted by ParseUnit.
+var aliases = map[string]Unit{
+	"mcg": Microgram, "µg": Microgram, "μg": Microgram, "ug": Microgram,
+	"microgram": Microgram, "micrograms": Microgram,
+	"mg": Milligram, "milligram": Milligram, "milligrams": Milligram,
+	"g": Gram, "gm": Gram, "gram": Gram, "grams": Gram,
+	"ml": Milliliter, "milliliter": Milliliter, "milliliters": Milliliter,
+	"millilitre": Milliliter, "millilitres": Milliliter, "cc": Milliliter,
+	"l": Liter, "liter": Liter, "liters": Liter, "litre": Liter, "litres": Liter,
+	"iu": InternationalUnit, "i.u.": InternationalUnit, "unit": InternationalUnit, "units": InternationalUnit,
+	"meq": Milliequivalent,
+}
+
+// ParseUnit accepts the canonical unit symbols and their common spellings.
+func ParseUnit(s string) (Unit, error) {
+	if u, ok := aliases[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return u, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownUnit, s)
+}
+
+// Quantity is an amount of one unit.
+type Quantity struct {
+	Amount float64 `json:"amount"`
+	Unit   Unit    `json:"unit"`
+}
+
+// Strength is an amount of active substance, optionally per a volume of the
+// product, e.g. 250 mg per 5 mL.
+type Strength struct {
+	Amount float64   `json:"amount"`
+	Unit   Unit      `json:"unit"`
+	Per    *Quantity `json:"per,omitempty"`
+}
+
+var (
+	quantityRe = regexp.MustCompile(`^(\d+(?:,\d{3})*(?:\.\d+)?|\.\d+)?\s*([a-zA-Zµμ.]+)$`)
+	perRe      = regexp.MustCompile(`\s*(?:/|\bper\b)\s*`)
+)
+
+// Parse reads a label strength. The optional denominator must be a volume
+// and defaults to an amount of 1, as in "5 mg/mL".
+func Parse(s string) (Strength, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Strength{}, fmt.Errorf("%w: empty", ErrInvalid)
+	}
+
+	parts := perRe.Split(s, -1)
+	if len(parts) > 2 {
+		return Strength{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+
+	num, err := parseQuantity(parts[0], false)
+	if err != nil {
+		return Strength{}, fmt.Errorf("%w: %q", err, s)
+	}
+
+	st := Strength{Amount: num.Amount, Unit: num.Unit}
+
+	if len(parts) == 2 {
+		per, err := parseQuantity(parts[1], true)
+		if err != nil {
+			return Strength{}, fmt.Errorf("%w: %q", err, s)
+		}
+		if units[per.Unit].kind != kindVolume {
+			return Strength{}, fmt.Errorf("%w: denominator must be a volume: %q", ErrInvalid, s)
+		}
+		st.Per = &per
+	}
+
+	if err = st.Validate(); err != nil {
+		return Strength{}, err
+	}
+
+	return st, nil
+}
+
+func parseQuantity(s string, amountOptional bool) (Quantity, error) {
+	m := quantityRe.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Quantity{}, ErrInvalid
+	}
+
+	amount := 1.0
+	switch {
+	case m[1] != "":
+		v, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+		if err != nil {
+			return Quantity{}, ErrInvalid
+		}
+		amount = v
+	case !amountOptional:
+		return Quantity{}, ErrInvalid
+	}
+
+	unit, err := ParseUnit(m[2])
+	if err != nil {
+		return Quantity{}, err
+	}
+
+	return Quantity{Amount: amount, Unit: unit}, nil
+}
+
+// Validate checks that amounts are positive and units are known.
+func (s Strength) Validate() error {
+	if _, ok := units[s.Unit]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownUnit, s.Unit)
+	}
+	if !(s.Amount > 0) || math.IsInf(s.Amount, 0) {
+		return fmt.Errorf("%w: amount must be positive", ErrInvalid)
+	}
+	if s.Per != nil {
+		if units[s.Per.Unit].kind != kindVolume {
+			return fmt.Errorf("%w: denominator must be a volume", ErrInvalid)
+		}
+		if !(s.Per.Amount > 0) || math.IsInf(s.Per.Amount, 0) {
+			return fmt.Errorf("%w: denominator amount must be positive", ErrInvalid)
+		}
+	}
+	return nil
+}
+
+// String formats the strength canonically, e.g. "200 mg", "5 mg/mL" or
+// "250 mg/5 mL".
+func (s Strength) String() string {
+	out := formatAmount(s.Amount) + " " + string(s.Unit)
+	if s.Per != nil {
+		out += "/"
+		if s.Per.Amount != 1 {
+			out += formatAmount(s.Per.Amount) + " "
+		}
+		out += string(s.Per.Unit)
+	}
+	return out
+}
+
+// Convert converts an amount between two units of the same kind. IU cannot
+// be converted to mass without knowing the substance.
+func Convert(amount float64, from, to Unit) (float64, error) {
+	f, ok := units[from]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownUnit, from)
+	}
+	t, ok := units[to]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownUnit, to)
+	}
+	if f.kind != t.kind {
+		return 0, fmt.Errorf("%w: %s to %s", ErrIncompatible, from, to)
+	}
+	return round(amount * f.factor / t.factor), nil
+}
+
+// In returns the strength with its amount expressed in unit.
+func (s Strength) In(unit Unit) (Strength, error) {
+	amount, err := Convert(s.Amount, s.Unit, unit)
+	if err != nil {
+		return Strength{}, err
+	}
+	s.Amount, s.Unit = amount, unit
+	return s, nil
+}
+
+// PerOne returns the strength scaled to a denominator of one perUnit, e.g.
+// 250 mg/5 mL becomes 50 mg/mL. A strength without a denominator is returned
+// unchanged.
+func (s Strength) PerOne(perUnit Unit) (Strength, error) {
+	if s.Per == nil {
+		return s, nil
+	}
+	per, err := Convert(s.Per.Amount, s.Per.Unit, perUnit)
+	if err != nil {
+		return Strength{}, err
+	}
+	s.Amount = round(s.Amount / per)
+	s.Per = &Quantity{Amount: 1, Unit: perUnit}
+	return s, nil
+}
+
+// Compare orders two strengths of the same kind by amount, or by
+// concentration when both have a denominator.
+func (s Strength) Compare(other Strength) (int, error) {
+	if (s.Per == nil) != (other.Per == nil) {
+		return 0, fmt.Errorf("%w: cannot compare an amount with a concentration", ErrIncompatible)
+	}
+
+	base := smallestUnit(s.Unit)
+	a, err := s.In(base)
+	if err != nil {
+		return 0, err
+	}
+	b, err := other.In(base)
+	if err != nil {
+		return 0, err
+	}
+	if a, err = a.PerOne(Milliliter); err != nil {
+		return 0, err
+	}
+	if b, err = b.PerOne(Milliliter); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case a.Amount < b.Amount:
+		return -1, nil
+	case a.Amount > b.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func smallestUnit(u Unit) Unit {
+	for unit, info := range units {
+		if info.kind == units[u].kind && info.factor == 1 {
+			return unit
+		}
+	}
+	return u
+}
+
+// round drops floating point noise below a billionth.
+func round(v float64) float64 {
+	return math.Round(v*1e9) / 1e9
+}
+
+func formatAmount(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
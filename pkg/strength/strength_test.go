@@ -0,0 +1,150 @@
+package strength
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"200mg", "200 mg"},
+		{"0.5 mcg", "0.5 mcg"},
+		{".5 µg", "0.5 mcg"},
+		{"1,000 IU", "1000 IU"},
+		{"10 mEq", "10 mEq"},
+		{"5 mg/mL", "5 mg/mL"},
+		{"250 mg/5 mL", "250 mg/5 mL"},
+		{"250 milligrams per 5 ml", "250 mg/5 mL"},
+		{"2 g / 1 L", "2 g/L"},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.in, got.String(), tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{"", ErrInvalid},
+		{"mg", ErrInvalid},
+		{"0 mg", ErrInvalid},
+		{"1%", ErrInvalid},
+		{"875 mg/125 mg", ErrInvalid},
+		{"5 mg/mL/day", ErrInvalid},
+		{"10 drops", ErrUnknownUnit},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.in); !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		from, to Unit
+		want     float64
+	}{
+		{1, Gram, Milligram, 1000},
+		{250, Milligram, Gram, 0.25},
+		{0.1, Milligram, Microgram, 100},
+		{1.5, Liter, Milliliter, 1500},
+		{400, InternationalUnit, InternationalUnit, 400},
+	}
+
+	for _, tt := range tests {
+		got, err := Convert(tt.amount, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%v, %s, %s) error: %v", tt.amount, tt.from, tt.to, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Convert(%v, %s, %s) = %v, want %v", tt.amount, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestConvertIncompatible(t *testing.T) {
+	tests := []struct {
+		from, to Unit
+		want     error
+	}{
+		{Milligram, Milliliter, ErrIncompatible},
+		{InternationalUnit, Milligram, ErrIncompatible},
+		{Milliequivalent, Milligram, ErrIncompatible},
+		{"oz", Milligram, ErrUnknownUnit},
+	}
+
+	for _, tt := range tests {
+		if _, err := Convert(1, tt.from, tt.to); !errors.Is(err, tt.want) {
+			t.Errorf("Convert(1, %s, %s) error = %v, want %v", tt.from, tt.to, err, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1 g", "1000 mg", 0},
+		{"500 mcg", "1 mg", -1},
+		{"2 mg", "1500 mcg", 1},
+		{"250 mg/5 mL", "50 mg/mL", 0},
+		{"125 mg/5 mL", "50 mg/mL", -1},
+		{"1 g/L", "0.5 mg/mL", 1},
+	}
+
+	for _, tt := range tests {
+		a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+		got, err := a.Compare(b)
+		if err != nil {
+			t.Errorf("Compare(%q, %q) error: %v", tt.a, tt.b, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareIncompatible(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"5 mg", "5 mg/mL"},
+		{"5 mg", "5 mL"},
+		{"400 IU", "10 mg"},
+	}
+
+	for _, tt := range tests {
+		if _, err := mustParse(t, tt.a).Compare(mustParse(t, tt.b)); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("Compare(%q, %q) error = %v, want %v", tt.a, tt.b, err, ErrIncompatible)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) Strength {
+	t.Helper()
+
+	st, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q) error: %v", s, err)
+	}
+	return st
+}
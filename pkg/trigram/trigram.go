@@ -0,0 +1,83 @@
+// Package trigram mirrors the trigram matching of the Postgres pg_trgm
+// extension, so fuzzy matching can be reproduced without a database.
+package trigram
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Threshold is the pg_trgm default for pg_trgm.word_similarity_threshold.
+const Threshold = 0.6
+
+// Words splits s into lower-cased alphanumeric words, the way pg_trgm does.
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Extract returns the ordered trigrams of s. Every word is padded with two
+// spaces in front and one behind before it is split.
+func Extract(s string) []string {
+	var out []string
+	for _, w := range Words(s) {
+		r := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(r); i++ {
+			out = append(out, string(r[i:i+3]))
+		}
+	}
+	return out
+}
+
+// Similarity is the share of trigrams the two strings have in common,
+// as pg_trgm similarity(a, b).
+func Similarity(a, b string) float64 {
+	return jaccard(toSet(Extract(a)), toSet(Extract(b)))
+}
+
+// WordSimilarity is the greatest similarity between the trigrams of a and
+// any continuous extent of the trigrams of b, as pg_trgm word_similarity(a, b).
+func WordSimilarity(a, b string) float64 {
+	needle := toSet(Extract(a))
+	hay := Extract(b)
+	if len(needle) == 0 || len(hay) == 0 {
+		return 0
+	}
+
+	var best float64
+	for i := range hay {
+		extent := make(map[string]struct{})
+		for j := i; j < len(hay); j++ {
+			extent[hay[j]] = struct{}{}
+			if sim := jaccard(needle, extent); sim > best {
+				best = sim
+			}
+		}
+	}
+
+	return best
+}
+
+func toSet(trgms []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(trgms))
+	for _, t := range trgms {
+		set[t] = struct{}{}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	common := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			common++
+		}
+	}
+
+	return float64(common) / float64(len(a)+len(b)-common)
+}
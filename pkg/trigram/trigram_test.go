@@ -0,0 +1,80 @@
+package trigram
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Ibuprofen", []string{"ibuprofen"}},
+		{"Co-Amoxiclav 500mg", []string{"co", "amoxiclav", "500mg"}},
+		{"  ", []string{}},
+	}
+
+	for _, tt := range tests {
+		got := Words(tt.in)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Words(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	want := []string{"  c", " ca", "cat", "at "}
+	if got := Extract("Cat"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Extract(%q) = %q, want %q", "Cat", got, want)
+	}
+}
+
+// The expected values are what pg_trgm returns for the same input.
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(a, b string) float64
+		a, b string
+		want float64
+	}{
+		{"identical", Similarity, "word", "word", 1},
+		{"disjoint", Similarity, "word", "xyz", 0},
+		{"empty", Similarity, "", "word", 0},
+		{"similarity", Similarity, "word", "two words", 4.0 / 11},
+		{"word similarity", WordSimilarity, "word", "two words", 0.8},
+		{"word similarity empty", WordSimilarity, "word", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWordSimilarityTypos(t *testing.T) {
+	tests := []struct {
+		query, text string
+	}{
+		{"ibuprofn", "Ibuprofen"},
+		{"amoxicilin", "Amoxicillin Trihydrate"},
+		{"paracetamol", "Paracetamol 500"},
+	}
+
+	for _, tt := range tests {
+		if got := WordSimilarity(tt.query, tt.text); got < Threshold {
+			t.Errorf("WordSimilarity(%q, %q) = %v, want at least %v", tt.query, tt.text, got, Threshold)
+		}
+	}
+
+	if got := WordSimilarity("ibuprofn", "Acetaminophen"); got >= Threshold {
+		t.Errorf("WordSimilarity(%q, %q) = %v, want below %v", "ibuprofn", "Acetaminophen", got, Threshold)
+	}
+}